@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 	"github.com/phuslu/log"
@@ -20,9 +21,12 @@ type Storage interface {
 	Insert(user *User) error
 	Update(user bson.D, id primitive.ObjectID) error
 	Delete(id primitive.ObjectID) error
-	GetUser(userName string) (User, error)
+	GetUser(chatID int) (User, error)
 	GetSubscribedUsers(ctx context.Context) ([]User, error)
 	UserSubscriptionStatus(id primitive.ObjectID) (int, error)
+	PurgeInactive(ctx context.Context, before time.Time) (int64, error)
+	PurgeAbandoned(ctx context.Context, before time.Time) (int64, error)
+	Touch(id primitive.ObjectID, at time.Time) error
 }
 
 // DB struct for database name and Client
@@ -131,11 +135,50 @@ func (db *DB) Delete(id primitive.ObjectID) error {
 	return nil
 }
 
-// GetUser returns single user from DB
-func (db *DB) GetUser(userName string) (User, error) {
+// PurgeInactive deletes users who unsubscribed before given time. Returns number of deleted users
+func (db *DB) PurgeInactive(ctx context.Context, before time.Time) (int64, error) {
+	collection := db.Client.Database(db.Database).Collection(db.Collection)
+	filter := bson.D{{"inactive", true}, {"unsubscribedAt", bson.D{{"$lt", before}}}}
+
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// PurgeAbandoned deletes users who didn't complete subscription and didn't interact with bot since given time.
+// Users who never interacted after sign up are checked by creation time of the document
+func (db *DB) PurgeAbandoned(ctx context.Context, before time.Time) (int64, error) {
+	collection := db.Client.Database(db.Database).Collection(db.Collection)
+	filter := bson.D{
+		{"subscriptionStatus", bson.D{{"$ne", int(LocationProvided)}}},
+		{"inactive", bson.D{{"$ne", true}}},
+		{"$or", bson.A{
+			bson.D{{"lastInteraction", bson.D{{"$lt", before}}}},
+			bson.D{{"lastInteraction", bson.D{{"$exists", false}}}, {"_id", bson.D{{"$lt", primitive.NewObjectIDFromTimestamp(before)}}}},
+		}},
+	}
+
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Touch stores time of user's last interaction with bot
+func (db *DB) Touch(id primitive.ObjectID, at time.Time) error {
+	return db.Update(bson.D{{"$set", bson.D{{"lastInteraction", at}}}}, id)
+}
+
+// GetUser returns single user subscribed in a chat from DB
+func (db *DB) GetUser(chatID int) (User, error) {
 	var result User
 	collection := db.Client.Database(db.Database).Collection(db.Collection)
-	filter := bson.D{{"username", userName}}
+	filter := bson.D{{"chatID", chatID}}
 	findErr := collection.FindOne(context.TODO(), filter).Decode(&result)
 	if findErr != nil {
 		return User{}, db.convertErr(findErr)
@@ -160,7 +203,7 @@ func (db *DB) convertErr(err error) error {
 // GetSubscribedUsers returns subscribed users from DB
 func (db *DB) GetSubscribedUsers(ctx context.Context) ([]User, error) {
 	var subscribers []User
-	filter := bson.D{{"subscriptionStatus", 4}}
+	filter := bson.D{{"subscriptionStatus", 4}, {"inactive", bson.D{{"$ne", true}}}}
 	collection := db.Client.Database(db.Database).Collection(db.Collection)
 	cursor, findErr := collection.Find(ctx, filter)
 	if findErr != nil {
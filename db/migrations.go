@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrate updates existing documents to the current schema
+func (db *DB) Migrate(ctx context.Context) error {
+	collection := db.Client.Database(db.Database).Collection(db.Collection)
+
+	if err := migrateUserIDs(ctx, collection); err != nil {
+		return err
+	}
+	if err := removeDuplicateChats(ctx, collection); err != nil {
+		return err
+	}
+
+	//Bot works without the index, so it is not a reason to stop
+	_, indexErr := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"chatID", 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if indexErr != nil {
+		log.Error().Err(indexErr).Msg("unable to create chatID index")
+	}
+
+	return nil
+}
+
+// removeDuplicateChats removes documents with the same chat ID created when users were keyed by username
+func removeDuplicateChats(ctx context.Context, collection *mongo.Collection) error {
+	pipeline := mongo.Pipeline{
+		{{"$group", bson.D{{"_id", "$chatID"}, {"count", bson.D{{"$sum", 1}}}}}},
+		{{"$match", bson.D{{"count", bson.D{{"$gt", 1}}}}}},
+	}
+	cursor, aggregateErr := collection.Aggregate(ctx, pipeline)
+	if aggregateErr != nil {
+		return fmt.Errorf("unable to find duplicate chats: %w", aggregateErr)
+	}
+	var groups []struct {
+		ChatID int `bson:"_id"`
+	}
+	if decodeErr := cursor.All(ctx, &groups); decodeErr != nil {
+		return fmt.Errorf("unable to decode duplicate chats: %w", decodeErr)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	chatIDs := make([]int, 0, len(groups))
+	for _, group := range groups {
+		chatIDs = append(chatIDs, group.ChatID)
+	}
+	usersCursor, findErr := collection.Find(ctx, bson.D{{"chatID", bson.D{{"$in", chatIDs}}}})
+	if findErr != nil {
+		return fmt.Errorf("unable to find duplicate chats: %w", findErr)
+	}
+	var users []User
+	if decodeErr := usersCursor.All(ctx, &users); decodeErr != nil {
+		return fmt.Errorf("unable to decode duplicate chats: %w", decodeErr)
+	}
+
+	result, deleteErr := collection.DeleteMany(ctx, bson.D{{"_id", bson.D{{"$in", duplicates(users)}}}})
+	if deleteErr != nil {
+		return fmt.Errorf("unable to remove duplicate chats: %w", deleteErr)
+	}
+	log.Info().Msgf("Duplicate documents removed for %v chats: %v", len(chatIDs), result.DeletedCount)
+
+	return nil
+}
+
+// duplicates returns IDs of documents to remove so that a single document is kept for every chat.
+// Document with completed subscription is kept, the newest one if there are several
+func duplicates(users []User) []primitive.ObjectID {
+	kept := make(map[int]User)
+	for _, user := range users {
+		current, found := kept[user.ChatID]
+		if !found || preferred(user, current) {
+			kept[user.ChatID] = user
+		}
+	}
+
+	removed := []primitive.ObjectID{}
+	for _, user := range users {
+		if kept[user.ChatID].ID != user.ID {
+			removed = append(removed, user.ID)
+		}
+	}
+	return removed
+}
+
+func preferred(user, current User) bool {
+	subscribed := user.SubscriptionStatus == int(LocationProvided)
+	if subscribed != (current.SubscriptionStatus == int(LocationProvided)) {
+		return subscribed
+	}
+	return user.ID.Timestamp().After(current.ID.Timestamp())
+}
+
+// migrateUserIDs sets Telegram user ID for users keyed by username.
+// Users subscribed in private chats only, where chat ID is the same as user ID
+func migrateUserIDs(ctx context.Context, collection *mongo.Collection) error {
+	filter := bson.D{{"userID", bson.D{{"$exists", false}}}}
+	update := mongo.Pipeline{
+		{{"$set", bson.D{{"userID", "$chatID"}}}},
+	}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("unable to migrate user IDs: %w", err)
+	}
+	log.Info().Msgf("User IDs migrated for %v users", result.ModifiedCount)
+
+	return nil
+}
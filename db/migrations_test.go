@@ -0,0 +1,53 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDuplicates(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := []primitive.ObjectID{
+		primitive.NewObjectIDFromTimestamp(created),
+		primitive.NewObjectIDFromTimestamp(created.AddDate(0, 0, 1)),
+		primitive.NewObjectIDFromTimestamp(created.AddDate(0, 0, 2)),
+	}
+
+	tests := []struct {
+		name  string
+		users []User
+		want  []primitive.ObjectID
+	}{
+		{
+			name:  "no duplicates",
+			users: []User{{ID: ids[0], ChatID: 1}, {ID: ids[1], ChatID: 2}},
+			want:  []primitive.ObjectID{},
+		},
+		{
+			name: "newest is kept",
+			users: []User{
+				{ID: ids[0], ChatID: 1, SubscriptionStatus: int(Subscribed)},
+				{ID: ids[2], ChatID: 1, SubscriptionStatus: int(NewUser)},
+				{ID: ids[1], ChatID: 1, SubscriptionStatus: int(TimeUpdated)},
+			},
+			want: []primitive.ObjectID{ids[0], ids[1]},
+		},
+		{
+			name: "completed subscription is kept",
+			users: []User{
+				{ID: ids[0], ChatID: 1, SubscriptionStatus: int(LocationProvided)},
+				{ID: ids[1], ChatID: 1, SubscriptionStatus: int(NewUser)},
+				{ID: ids[2], ChatID: 2, SubscriptionStatus: int(NewUser)},
+			},
+			want: []primitive.ObjectID{ids[1]},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, duplicates(tc.users))
+		})
+	}
+}
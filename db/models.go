@@ -9,13 +9,31 @@ import (
 // User struct for DB
 type User struct {
 	ID                 primitive.ObjectID `bson:"_id,omitempty"`
+	UserID             int                `bson:"userID"`
 	Username           string             `bson:"username"`
 	SubscriptionStatus int                `bson:"subscriptionStatus"`
 	UserTime           string             `bson:"userTime"`
 	Location           Location           `bson:"location"`
 	City               string             `bson:"city"`
+	Place              string             `bson:"place"`
+	LocationLocked     bool               `bson:"locationLocked"`
+	LiveLocation       LiveLocation       `bson:"liveLocation"`
 	ChatID             int                `bson:"chatID"`
 	ForecastSentAt     time.Time          `bson:"forecastSentAt"`
+	Observations       []Observation      `bson:"observations"`
+	SwingThreshold     int                `bson:"swingThreshold"`
+	Sunrise            time.Time          `bson:"sunrise"`
+	Sunset             time.Time          `bson:"sunset"`
+	GoldenHourReminder int                `bson:"goldenHourReminder"`
+	GoldenHourSentAt   time.Time          `bson:"goldenHourSentAt"`
+	Format             string             `bson:"format"`
+	Places             []Place            `bson:"places"`
+	Trips              []Trip             `bson:"trips"`
+	Commute            Commute            `bson:"commute"`
+	Units              string             `bson:"units"`
+	Inactive           bool               `bson:"inactive"`
+	UnsubscribedAt     time.Time          `bson:"unsubscribedAt"`
+	LastInteraction    time.Time          `bson:"lastInteraction,omitempty"`
 }
 
 // Config struct for DB config
@@ -32,3 +50,45 @@ type Location struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
 }
+
+// Observation struct for a compact daily temperature observation of a place in user's units
+type Observation struct {
+	Date  string  `bson:"date"`
+	Place string  `bson:"place"`
+	Temp  float64 `bson:"temp"`
+	Units string  `bson:"units"`
+}
+
+// Place struct for a named place saved by user
+type Place struct {
+	Name     string   `bson:"name"`
+	City     string   `bson:"city"`
+	Location Location `bson:"location"`
+	Included bool     `bson:"included"`
+}
+
+// Trip struct for a temporary forecast location within dates, inclusive
+type Trip struct {
+	City string    `bson:"city"`
+	From time.Time `bson:"from"`
+	To   time.Time `bson:"to"`
+}
+
+// Commute struct for a daily trip to a place and back. Departure is from the first place, return from the second
+type Commute struct {
+	Departure CommuteLeg `bson:"departure"`
+	Return    CommuteLeg `bson:"return"`
+}
+
+// CommuteLeg struct for a place and time of day, UTC, a commute starts from
+type CommuteLeg struct {
+	City string `bson:"city"`
+	Time string `bson:"time"`
+}
+
+// LiveLocation struct for position shared live in the chat until given time by a user or a chat
+type LiveLocation struct {
+	Location Location  `bson:"location"`
+	Until    time.Time `bson:"until"`
+	SharedBy int       `bson:"sharedBy"`
+}
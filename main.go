@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"subscriptionbot/db"
 	"subscriptionbot/service"
+	"subscriptionbot/telegram"
 	"subscriptionbot/utilities"
 	weatherAPI "subscriptionbot/weather"
 	"time"
@@ -38,17 +39,47 @@ func main() {
 
 	api := tgapi.GetAPI(cfg)
 	database := db.GetDB()
-	weather := weatherAPI.GetWeatherAPI()
+	if err := database.Migrate(ctx); err != nil {
+		log.Fatal().Err(err).Msg("database migration failed")
+	}
+	breakerCfg := weatherAPI.BreakerConfig{}
+	if err := env.Parse(&breakerCfg); err != nil {
+		log.Error().Err(err)
+	}
+	provider := weatherAPI.GetWeatherAPI()
+	weather := weatherAPI.NewBreaker(provider, breakerCfg)
+
+	bot := telegram.GetClient(cfg)
 
-	tgService := service.NewService(database, weather, api)
+	tgService := service.NewService(database, weather, api, bot)
+	limitCfg := service.RateLimitConfig{}
+	if err := env.Parse(&limitCfg); err != nil {
+		log.Error().Err(err).Msg("unable to parse rate limit config")
+	}
+	tgService.Limiter = service.NewRateLimiter(limitCfg)
+	adminCfg := service.AdminCacheConfig{}
+	if err := env.Parse(&adminCfg); err != nil {
+		log.Error().Err(err).Msg("unable to parse admin cache config")
+	}
+	tgService.Admins = service.NewAdminCache(adminCfg)
+	if err := env.Parse(&tgService.Retention); err != nil {
+		log.Error().Err(err).Msg("unable to parse retention config")
+	}
 
 	go func() {
 		tgService.Notify(ctx)
 	}()
+	go tgService.Purge(ctx)
 
-	api.RegisterCommand("/start", utilities.StartResponse)
-	api.RegisterInput(tgService.AddSubscription)
-	http.HandleFunc("/telegram", api.TelegramHandler)
+	dispatcher := telegram.NewDispatcher(api)
+	dispatcher.RegisterCommand("/start", utilities.StartResponse)
+	dispatcher.RegisterInput(tgService.AddSubscription)
+	dispatcher.RegisterMemberUpdate(tgService.MemberUpdate)
+	dispatcher.RegisterCallback(tgService.Callback)
+	dispatcher.RegisterLiveLocation(tgService.LiveLocation)
+	http.HandleFunc("/telegram", dispatcher.TelegramHandler)
+	http.HandleFunc("/health/weather", weather.HealthHandler)
+	http.HandleFunc("/health/weather/keys", provider.Keys().UsageHandler)
 
 	go func() {
 		err := http.ListenAndServe(fmt.Sprintf(":%v", cfg.Port), nil)
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: git.foxminded.ua/foxstudent106270/telegramapi.git (interfaces: TelegramService)
+// Source: github.com/c1kzy/Telegram-API (interfaces: TelegramService)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: subscriptionbot/telegram (interfaces: BotService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	telegram "subscriptionbot/telegram"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// BotService is a mock of BotService interface.
+type BotService struct {
+	ctrl     *gomock.Controller
+	recorder *BotServiceMockRecorder
+}
+
+// BotServiceMockRecorder is the mock recorder for BotService.
+type BotServiceMockRecorder struct {
+	mock *BotService
+}
+
+// NewBotService creates a new mock instance.
+func NewBotService(ctrl *gomock.Controller) *BotService {
+	mock := &BotService{ctrl: ctrl}
+	mock.recorder = &BotServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *BotService) EXPECT() *BotServiceMockRecorder {
+	return m.recorder
+}
+
+// AnswerCallbackQuery mocks base method.
+func (m *BotService) AnswerCallbackQuery(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnswerCallbackQuery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnswerCallbackQuery indicates an expected call of AnswerCallbackQuery.
+func (mr *BotServiceMockRecorder) AnswerCallbackQuery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerCallbackQuery", reflect.TypeOf((*BotService)(nil).AnswerCallbackQuery), arg0, arg1)
+}
+
+// EditMessageText mocks base method.
+func (m *BotService) EditMessageText(arg0, arg1 int, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessageText", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditMessageText indicates an expected call of EditMessageText.
+func (mr *BotServiceMockRecorder) EditMessageText(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessageText", reflect.TypeOf((*BotService)(nil).EditMessageText), arg0, arg1, arg2, arg3)
+}
+
+// GetChatMember mocks base method.
+func (m *BotService) GetChatMember(arg0, arg1 int) (telegram.ChatMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatMember", arg0, arg1)
+	ret0, _ := ret[0].(telegram.ChatMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatMember indicates an expected call of GetChatMember.
+func (mr *BotServiceMockRecorder) GetChatMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatMember", reflect.TypeOf((*BotService)(nil).GetChatMember), arg0, arg1)
+}
+
+// SendPhoto mocks base method.
+func (m *BotService) SendPhoto(arg0 int, arg1 []byte, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPhoto", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPhoto indicates an expected call of SendPhoto.
+func (mr *BotServiceMockRecorder) SendPhoto(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPhoto", reflect.TypeOf((*BotService)(nil).SendPhoto), arg0, arg1, arg2)
+}
+
+// SendPhotoURL mocks base method.
+func (m *BotService) SendPhotoURL(arg0 int, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPhotoURL", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPhotoURL indicates an expected call of SendPhotoURL.
+func (mr *BotServiceMockRecorder) SendPhotoURL(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPhotoURL", reflect.TypeOf((*BotService)(nil).SendPhotoURL), arg0, arg1, arg2)
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 	db "subscriptionbot/db"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
@@ -66,7 +67,7 @@ func (mr *MongoStorageMockRecorder) GetSubscribedUsers(arg0 interface{}) *gomock
 }
 
 // GetUser mocks base method.
-func (m *MongoStorage) GetUser(arg0 string) (db.User, error) {
+func (m *MongoStorage) GetUser(arg0 int) (db.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUser", arg0)
 	ret0, _ := ret[0].(db.User)
@@ -94,6 +95,50 @@ func (mr *MongoStorageMockRecorder) Insert(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MongoStorage)(nil).Insert), arg0)
 }
 
+// PurgeAbandoned mocks base method.
+func (m *MongoStorage) PurgeAbandoned(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeAbandoned", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeAbandoned indicates an expected call of PurgeAbandoned.
+func (mr *MongoStorageMockRecorder) PurgeAbandoned(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeAbandoned", reflect.TypeOf((*MongoStorage)(nil).PurgeAbandoned), arg0, arg1)
+}
+
+// PurgeInactive mocks base method.
+func (m *MongoStorage) PurgeInactive(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeInactive", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeInactive indicates an expected call of PurgeInactive.
+func (mr *MongoStorageMockRecorder) PurgeInactive(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeInactive", reflect.TypeOf((*MongoStorage)(nil).PurgeInactive), arg0, arg1)
+}
+
+// Touch mocks base method.
+func (m *MongoStorage) Touch(arg0 primitive.ObjectID, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MongoStorageMockRecorder) Touch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MongoStorage)(nil).Touch), arg0, arg1)
+}
+
 // Update mocks base method.
 func (m *MongoStorage) Update(arg0 primitive.D, arg1 primitive.ObjectID) error {
 	m.ctrl.T.Helper()
@@ -5,9 +5,11 @@
 package mocks
 
 import (
+	context "context"
 	url "net/url"
 	reflect "reflect"
 	db "subscriptionbot/db"
+	weatherAPI "subscriptionbot/weather"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,17 +37,47 @@ func (m *WeatherService) EXPECT() *WeatherServiceMockRecorder {
 	return m.recorder
 }
 
+// CurrentWeather mocks base method.
+func (m *WeatherService) CurrentWeather(arg0 context.Context, arg1 db.User) (weatherAPI.WeatherData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CurrentWeather", arg0, arg1)
+	ret0, _ := ret[0].(weatherAPI.WeatherData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CurrentWeather indicates an expected call of CurrentWeather.
+func (mr *WeatherServiceMockRecorder) CurrentWeather(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentWeather", reflect.TypeOf((*WeatherService)(nil).CurrentWeather), arg0, arg1)
+}
+
+// Forecast mocks base method.
+func (m *WeatherService) Forecast(arg0 context.Context, arg1 db.User) (weatherAPI.ForecastData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Forecast", arg0, arg1)
+	ret0, _ := ret[0].(weatherAPI.ForecastData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Forecast indicates an expected call of Forecast.
+func (mr *WeatherServiceMockRecorder) Forecast(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forecast", reflect.TypeOf((*WeatherService)(nil).Forecast), arg0, arg1)
+}
+
 // WeatherRequest mocks base method.
-func (m *WeatherService) WeatherRequest(arg0 db.User) (url.Values, error) {
+func (m *WeatherService) WeatherRequest(arg0 context.Context, arg1 db.User) (url.Values, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WeatherRequest", arg0)
+	ret := m.ctrl.Call(m, "WeatherRequest", arg0, arg1)
 	ret0, _ := ret[0].(url.Values)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // WeatherRequest indicates an expected call of WeatherRequest.
-func (mr *WeatherServiceMockRecorder) WeatherRequest(arg0 interface{}) *gomock.Call {
+func (mr *WeatherServiceMockRecorder) WeatherRequest(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WeatherRequest", reflect.TypeOf((*WeatherService)(nil).WeatherRequest), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WeatherRequest", reflect.TypeOf((*WeatherService)(nil).WeatherRequest), arg0, arg1)
 }
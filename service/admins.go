@@ -0,0 +1,68 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// AdminCacheConfig struct for how long chat member status is trusted before it is checked again
+type AdminCacheConfig struct {
+	TTL time.Duration `env:"ADMIN_CACHE_TTL" envDefault:"5m"`
+}
+
+// AdminCache keeps admin status of chat members, so every group update doesn't call Bot API
+type AdminCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	members  map[chatMember]memberStatus
+	prunedAt time.Time
+}
+
+type chatMember struct {
+	chatID int
+	userID int
+}
+
+type memberStatus struct {
+	admin     bool
+	checkedAt time.Time
+}
+
+func NewAdminCache(cfg AdminCacheConfig) *AdminCache {
+	return &AdminCache{
+		ttl:     cfg.TTL,
+		members: map[chatMember]memberStatus{},
+	}
+}
+
+// get returns admin status of chat member if it was checked within TTL
+func (c *AdminCache) get(chatID, userID int, now time.Time) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, found := c.members[chatMember{chatID: chatID, userID: userID}]
+	if !found || now.Sub(status.checkedAt) >= c.ttl {
+		return false, false
+	}
+	return status.admin, true
+}
+
+// set stores admin status of chat member. Expired members are removed once per TTL
+func (c *AdminCache) set(chatID, userID int, admin bool, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.prunedAt) >= c.ttl {
+		c.prunedAt = now
+		for member, status := range c.members {
+			if now.Sub(status.checkedAt) >= c.ttl {
+				delete(c.members, member)
+			}
+		}
+	}
+	c.members[chatMember{chatID: chatID, userID: userID}] = memberStatus{admin: admin, checkedAt: now}
+}
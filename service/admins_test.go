@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminCache(t *testing.T) {
+	cache := NewAdminCache(AdminCacheConfig{TTL: 5 * time.Minute})
+	now := time.Date(2023, 11, 20, 12, 0, 0, 0, time.UTC)
+	cache.set(-100123, 42, true, now)
+	cache.set(-100123, 43, false, now.Add(4*time.Minute))
+
+	tests := []struct {
+		name      string
+		userID    int
+		at        time.Time
+		want      bool
+		wantFound bool
+	}{
+		{name: "cached admin", userID: 42, at: now.Add(time.Minute), want: true, wantFound: true},
+		{name: "cached member", userID: 43, at: now.Add(5 * time.Minute), want: false, wantFound: true},
+		{name: "expired", userID: 42, at: now.Add(5 * time.Minute)},
+		{name: "unknown", userID: 44, at: now},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := cache.get(-100123, tc.userID, tc.at)
+			assert.Equal(t, tc.wantFound, found)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("expired members are pruned", func(t *testing.T) {
+		cache.set(-100123, 44, true, now.Add(8*time.Minute))
+		assert.Len(t, cache.members, 2)
+	})
+}
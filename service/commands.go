@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+)
+
+// timeCommand handles /time 07:30. While time is requested it moves the flow to location request
+func (s *Service) timeCommand(args string, user db.User, state db.SubscriptionStatus, chatID int) (Step, error) {
+	if state == db.NewUser {
+		return subscribeFirst(chatID), nil
+	}
+	if args == "" {
+		reply, replyErr := menuReply(chatID, fmt.Sprintf("Your forecast time is %v UTC. Choose new time or enter it. Example: /time 07:30", user.UserTime), utilities.HourPicker())
+		return Step{Reply: reply}, replyErr
+	}
+
+	step, stepErr := s.timeUpdate(args, chatID)
+	if stepErr != nil || state != db.Subscribed {
+		return step, stepErr
+	}
+
+	reply, replyErr := locationStepReply(chatID, step.Reply.Get("text")+".")
+	if replyErr != nil {
+		return Step{}, replyErr
+	}
+	step.Reply = reply
+	step.Next = db.TimeUpdated
+	return step, nil
+}
+
+// cityCommand handles /city New York. While location is requested it completes the flow
+func (s *Service) cityCommand(args string, user db.User, state db.SubscriptionStatus, chatID int) (Step, error) {
+	if state == db.NewUser {
+		return subscribeFirst(chatID), nil
+	}
+	if cities := cityChoices(user); args == "" && len(cities) > 0 {
+		reply, replyErr := menuReply(chatID, "Choose city or enter it after the command. Example: /city New York", utilities.ChoiceMenu(utilities.CityCallback, cities, user.City))
+		return Step{Reply: reply}, replyErr
+	}
+	if args == "" {
+		return Step{Reply: url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {"please enter city after the command or share location\nExample: /city New York"},
+		}}, nil
+	}
+
+	step, stepErr := s.placeUpdate(args, telegram.Location{}, nil, user, chatID)
+	if stepErr != nil {
+		return step, stepErr
+	}
+
+	switch state {
+	case db.Subscribed:
+		reply, replyErr := timeStepReply(chatID, "City updated.")
+		if replyErr != nil {
+			return Step{}, replyErr
+		}
+		step.Reply = reply
+	case db.TimeUpdated:
+		step.Next = db.LocationProvided
+		step.Reply.Set("text", "City updated")
+	}
+	return step, nil
+}
+
+// helpCommand handles /help
+func (s *Service) helpCommand(_ string, _ db.User, chatID int) (url.Values, error) {
+	subscribedButtons, jsonErr := utilities.ButtonMarshal(utilities.SubscribedMenu)
+	if jsonErr != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %w", jsonErr)
+	}
+
+	return url.Values{
+		"chat_id":      {strconv.Itoa(chatID)},
+		"text":         {utilities.SubscribedOptions},
+		"reply_markup": {string(subscribedButtons)},
+	}, nil
+}
+
+func subscribeFirst(chatID int) Step {
+	return Step{Reply: url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"text":    {"Please subscribe to continue"},
+	}}
+}
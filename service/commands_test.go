@@ -0,0 +1,135 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStateMachine_Handle_commands(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}, UserTime: "08:00"}
+
+	tests := []struct {
+		name       string
+		text       string
+		state      db.SubscriptionStatus
+		want       string
+		wantErr    bool
+		setupMocks func()
+	}{
+		{
+			name:       "time before subscribe",
+			text:       "/time 07:30",
+			state:      db.NewUser,
+			want:       "Please subscribe to continue",
+			setupMocks: func() {},
+		},
+		{
+			name:  "time while time is requested",
+			text:  "/time 07:30",
+			state: db.Subscribed,
+			want:  "User time updated to 07:30 UTC.\nStep 2 of 2 ●●. Please enter city or share location to update the city for weather forecast",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{
+					{"subscriptionStatus", db.TimeUpdated},
+					{"userTime", "07:30"},
+				}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:  "time when subscribed",
+			text:  "/time@weather_bot 07:30",
+			state: db.LocationProvided,
+			want:  "User time updated to 07:30 UTC",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"userTime", "07:30"}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:  "time in words",
+			text:  "/time half past seven pm",
+			state: db.LocationProvided,
+			want:  "User time updated to 19:30 UTC",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"userTime", "19:30"}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:       "time without argument",
+			text:       "/time",
+			state:      db.LocationProvided,
+			want:       "Your forecast time is 08:00 UTC. Choose new time or enter it. Example: /time 07:30",
+			setupMocks: func() {},
+		},
+		{
+			name:       "invalid time",
+			text:       "/time soon",
+			state:      db.LocationProvided,
+			want:       "invalid time, try again.Example: 07:30, 7:30 pm or half past seven",
+			wantErr:    true,
+			setupMocks: func() {},
+		},
+		{
+			name:  "city while location is requested",
+			text:  "/city New York",
+			state: db.TimeUpdated,
+			want:  "City updated",
+			setupMocks: func() {
+				weather.EXPECT().WeatherRequest(gomock.Any(), gomock.Any()).Return(nil, nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{
+					{"subscriptionStatus", db.LocationProvided},
+					{"city", "New York"},
+				}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:  "city while time is requested",
+			text:  "/city New York",
+			state: db.Subscribed,
+			want:  "City updated.\nStep 1 of 2 ●○. Please pick hour below or enter time for weather forecast every day.Example: /time 15:00 or 7am.\nTime when subscribed is used by default, press Skip to keep it",
+			setupMocks: func() {
+				weather.EXPECT().WeatherRequest(gomock.Any(), gomock.Any()).Return(nil, nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"city", "New York"}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:       "city without argument",
+			text:       "/city",
+			state:      db.LocationProvided,
+			want:       "please enter city after the command or share location\nExample: /city New York",
+			setupMocks: func() {},
+		},
+		{
+			name:  "stop",
+			text:  "/stop",
+			state: db.TimeUpdated,
+			want:  "You have unsubscribed from weather forecast",
+			setupMocks: func() {
+				storage.EXPECT().Update(deactivation{}, user.ID).Return(nil)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			got, err := subscriptionFlow.Handle(tgService, requestBody(t, tc.text), user, tc.state, 358383178)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
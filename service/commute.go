@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/utilities"
+	weatherAPI "subscriptionbot/weather"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// commuteWindow is how far forecast step can be from commute time, forecast has 3-hour steps
+const commuteWindow = 90 * time.Minute
+
+const commuteUsage = "Example: /commute Kyiv 08:00 Brovary 18:00 to leave Kyiv at 08:00 and Brovary at 18:00 UTC, /commute off"
+
+// commuteCommand handles /commute <departure city> <time> <return city> <time>, /commute off and /commute
+func (s *Service) commuteCommand(args string, user db.User, chatID int) (url.Values, error) {
+	switch strings.ToLower(args) {
+	case "":
+		return placesReply(chatID, formatCommute(user.Commute)), nil
+	case utilities.Off:
+		if updateErr := s.updateCommute(user, db.Commute{}); updateErr != nil {
+			return nil, updateErr
+		}
+		return placesReply(chatID, "Commute removed from daily forecast"), nil
+	}
+
+	commute, parsed := parseCommute(args)
+	if !parsed {
+		return placesReply(chatID, fmt.Sprintf("invalid commute. %v", commuteUsage)), nil
+	}
+
+	//Checking if cities can be used in weather request
+	for _, leg := range []db.CommuteLeg{commute.Departure, commute.Return} {
+		if _, weatherErr := s.Weather.CurrentWeather(context.Background(), placeUser(user, db.Place{City: leg.City})); weatherErr != nil {
+			return placesReply(chatID, fmt.Sprintf("unable to find weather for %v", leg.City)), nil
+		}
+	}
+
+	if updateErr := s.updateCommute(user, commute); updateErr != nil {
+		return nil, updateErr
+	}
+	return placesReply(chatID, fmt.Sprintf("Commute saved. Daily forecast will show conditions for it\n\n%v", formatCommute(commute))), nil
+}
+
+func (s *Service) updateCommute(user db.User, commute db.Commute) error {
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"commute", commute},
+	}}}, user.ID)
+}
+
+// parseCommute splits "Kyiv 08:00 Brovary 18:00" into legs. Cities can have several words, each one is followed by time
+func parseCommute(args string) (db.Commute, bool) {
+	var (
+		legs []db.CommuteLeg
+		city []string
+	)
+	for _, field := range strings.Fields(args) {
+		first, _ := utf8.DecodeRuneInString(field)
+		legTime, timeErr := utilities.ConvertTime(field)
+		if !unicode.IsDigit(first) || timeErr != nil {
+			city = append(city, field)
+			continue
+		}
+		if len(city) == 0 {
+			return db.Commute{}, false
+		}
+		legs = append(legs, db.CommuteLeg{City: strings.Join(city, " "), Time: legTime})
+		city = nil
+	}
+
+	if len(legs) != 2 || len(city) > 0 {
+		return db.Commute{}, false
+	}
+	return db.Commute{Departure: legs[0], Return: legs[1]}, true
+}
+
+// commuteForecast renders conditions at each commute place around its time following the forecast
+func (s *Service) commuteForecast(ctx context.Context, user db.User, sentAt time.Time) string {
+	if user.Commute.Departure.City == "" {
+		return ""
+	}
+
+	lines := []string{"🚶Commute:"}
+	for _, leg := range []struct {
+		name string
+		leg  db.CommuteLeg
+	}{
+		{name: "Departure", leg: user.Commute.Departure},
+		{name: "Return", leg: user.Commute.Return},
+	} {
+		line, lineErr := s.commuteLeg(ctx, user, leg.name, leg.leg, sentAt)
+		if lineErr != nil {
+			log.Error().Err(lineErr).Msgf("unable to get commute forecast for %v of ChatID:%v", leg.leg.City, user.ChatID)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 1 {
+		return ""
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// commuteLeg renders forecast step closest to the next commute time, rain and snow are highlighted
+func (s *Service) commuteLeg(ctx context.Context, user db.User, name string, leg db.CommuteLeg, sentAt time.Time) (string, error) {
+	legTime, timeErr := time.Parse("15:04", leg.Time)
+	if timeErr != nil {
+		return "", timeErr
+	}
+
+	forecast, forecastErr := s.Weather.Forecast(ctx, placeUser(user, db.Place{City: leg.City}))
+	if forecastErr != nil {
+		return "", forecastErr
+	}
+
+	item, found := closestStep(forecast, sendNextTime(sentAt, legTime))
+	if !found {
+		return fmt.Sprintf("%v from %v at %v: no forecast yet", name, leg.City, leg.Time), nil
+	}
+
+	condition := item.Condition()
+	line := fmt.Sprintf("%v from %v at %v: %v%v, %v°", name, leg.City, leg.Time, condition.Emoji(), condition.Description, int(math.Round(item.Main.Temp)))
+	switch {
+	case item.Snowy():
+		line += "\n❄️Snow expected, allow extra time"
+	case item.Rainy():
+		line += "\n☔Rain expected, take an umbrella"
+	}
+	return line, nil
+}
+
+// closestStep returns forecast step closest to given time within commute window
+func closestStep(forecast weatherAPI.ForecastData, at time.Time) (weatherAPI.ForecastItem, bool) {
+	var (
+		closest weatherAPI.ForecastItem
+		found   bool
+	)
+	for _, item := range forecast.List {
+		distance := item.Time(0).Sub(at).Abs()
+		if distance > commuteWindow {
+			continue
+		}
+		if !found || distance < closest.Time(0).Sub(at).Abs() {
+			closest, found = item, true
+		}
+	}
+	return closest, found
+}
+
+func formatCommute(commute db.Commute) string {
+	if commute.Departure.City == "" {
+		return fmt.Sprintf("You have no commute. %v", commuteUsage)
+	}
+
+	return fmt.Sprintf("🚶Your commute:\nDeparture from %v at %v UTC\nReturn from %v at %v UTC",
+		commute.Departure.City, commute.Departure.Time, commute.Return.City, commute.Return.Time)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseCommute(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   string
+		want   db.Commute
+		parsed bool
+	}{
+		{
+			name:   "two places",
+			args:   "Kyiv 08:00 Brovary 18:00",
+			want:   db.Commute{Departure: db.CommuteLeg{City: "Kyiv", Time: "08:00"}, Return: db.CommuteLeg{City: "Brovary", Time: "18:00"}},
+			parsed: true,
+		},
+		{
+			name:   "cities with several words and 12h time",
+			args:   "New York 7:30am Jersey City 6pm",
+			want:   db.Commute{Departure: db.CommuteLeg{City: "New York", Time: "07:30"}, Return: db.CommuteLeg{City: "Jersey City", Time: "18:00"}},
+			parsed: true,
+		},
+		{name: "missing return", args: "Kyiv 08:00"},
+		{name: "missing city", args: "08:00 Brovary 18:00"},
+		{name: "missing time", args: "Kyiv 08:00 Brovary"},
+		{name: "invalid time", args: "Kyiv 08:00 Brovary 28:00"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, parsed := parseCommute(tc.args)
+			assert.Equal(t, tc.parsed, parsed)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestService_commuteCommand(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}, City: "Kyiv", ChatID: 358383178}
+	commute := db.Commute{Departure: db.CommuteLeg{City: "Kyiv", Time: "08:00"}, Return: db.CommuteLeg{City: "Brovary", Time: "18:00"}}
+
+	tests := []struct {
+		name       string
+		args       string
+		want       string
+		setupMocks func()
+	}{
+		{
+			name: "save commute",
+			args: "Kyiv 08:00 Brovary 18:00",
+			want: "Commute saved. Daily forecast will show conditions for it\n\n🚶Your commute:\nDeparture from Kyiv at 08:00 UTC\nReturn from Brovary at 18:00 UTC",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), placeUser(user, db.Place{City: "Kyiv"})).Return(weatherAPI.WeatherData{}, nil)
+				weather.EXPECT().CurrentWeather(gomock.Any(), placeUser(user, db.Place{City: "Brovary"})).Return(weatherAPI.WeatherData{}, nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"commute", commute}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name: "unknown city",
+			args: "Kyiv 08:00 Nowhere 18:00",
+			want: "unable to find weather for Nowhere",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), placeUser(user, db.Place{City: "Kyiv"})).Return(weatherAPI.WeatherData{}, nil)
+				weather.EXPECT().CurrentWeather(gomock.Any(), placeUser(user, db.Place{City: "Nowhere"})).Return(weatherAPI.WeatherData{}, weatherAPI.ErrNotFound)
+			},
+		},
+		{
+			name: "invalid commute",
+			args: "Kyiv morning",
+			want: "invalid commute. " + commuteUsage,
+		},
+		{
+			name: "remove commute",
+			args: "off",
+			want: "Commute removed from daily forecast",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"commute", db.Commute{}}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name: "show commute",
+			args: "",
+			want: "You have no commute. " + commuteUsage,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.setupMocks != nil {
+				tc.setupMocks()
+			}
+
+			got, err := tgService.commuteCommand(tc.args, user, user.ChatID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
+
+func TestService_commuteForecast(t *testing.T) {
+	controller := gomock.NewController(t)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(mocks.NewMongoStorage(controller), weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{
+		ID:      primitive.ObjectID{1},
+		City:    "Kyiv",
+		Commute: db.Commute{Departure: db.CommuteLeg{City: "Kyiv", Time: "08:00"}, Return: db.CommuteLeg{City: "Brovary", Time: "17:00"}},
+	}
+	sentAt := time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)
+	step := func(hour int, temp float64, condition weatherAPI.Weather) weatherAPI.ForecastItem {
+		return weatherAPI.ForecastItem{
+			Dt:      int(time.Date(2024, 1, 15, hour, 0, 0, 0, time.UTC).Unix()),
+			Main:    weatherAPI.Main{Temp: temp},
+			Weather: []weatherAPI.Weather{condition},
+		}
+	}
+	clouds := weatherAPI.Weather{ID: 804, Description: "overcast clouds", Icon: "04d"}
+	rain := weatherAPI.Weather{ID: 500, Description: "light rain", Icon: "10d"}
+	snow := weatherAPI.Weather{ID: 600, Description: "light snow", Icon: "13d"}
+
+	weather.EXPECT().Forecast(gomock.Any(), placeUser(user, db.Place{City: "Kyiv"})).Return(weatherAPI.ForecastData{List: []weatherAPI.ForecastItem{
+		step(6, 1, clouds), step(9, 2.6, rain), step(12, 4, clouds),
+	}}, nil)
+	weather.EXPECT().Forecast(gomock.Any(), placeUser(user, db.Place{City: "Brovary"})).Return(weatherAPI.ForecastData{List: []weatherAPI.ForecastItem{
+		step(15, -1, clouds), step(18, -2, snow), step(21, -3, clouds),
+	}}, nil)
+
+	got := tgService.commuteForecast(context.Background(), user, sentAt)
+	assert.Equal(t, "🚶Commute:\n"+
+		"Departure from Kyiv at 08:00: 🌧️light rain, 3°\n☔Rain expected, take an umbrella\n"+
+		"Return from Brovary at 17:00: ❄️light snow, -2°\n❄️Snow expected, allow extra time", got)
+
+	assert.Empty(t, tgService.commuteForecast(context.Background(), db.User{}, sentAt))
+}
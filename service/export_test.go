@@ -0,0 +1,4 @@
+package service
+
+// Deactivation matches update marking user inactive for tests of service_test package
+var Deactivation = deactivation{}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/utilities"
+	weatherAPI "subscriptionbot/weather"
+	"time"
+	"unicode/utf8"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const dateLayout = "2006-01-02"
+
+// captionLimit is max length of Telegram photo caption
+const captionLimit = 1024
+
+// sendForecast sends daily forecast to a subscriber with a day-over-day temperature change.
+// Commute is forecast for the day of sending, next trigger is stored as time forecast was sent for
+func (s *Service) sendForecast(ctx context.Context, user db.User, now, sentAt time.Time) error {
+	weather, weatherErr := s.Weather.CurrentWeather(ctx, user)
+	if weatherErr != nil {
+		return s.skipForecast(user, sentAt, weatherErr)
+	}
+
+	text := weatherAPI.FormatForecast(weather)
+	observation := newObservation(user, weather, sentAt)
+	delta, hasDelta := temperatureDelta(findObservation(user.Observations, observation.Place), observation)
+	//Stale data of unavailable provider is not today's observation
+	observations := user.Observations
+	if weather.Stale {
+		hasDelta = false
+	} else {
+		observations = addObservation(observations, observation, sentAt)
+	}
+	if hasDelta {
+		text = fmt.Sprintf("%v\n%v", text, deltaText(delta))
+	}
+	places, placeObservations := s.placesForecast(ctx, user, sentAt)
+	for _, observation := range placeObservations {
+		observations = addObservation(observations, observation, sentAt)
+	}
+	if places != "" {
+		text = fmt.Sprintf("%v\n\n%v", text, places)
+	}
+	if commute := s.commuteForecast(ctx, user, now); commute != "" {
+		text = fmt.Sprintf("%v\n\n%v", text, commute)
+	}
+
+	if sendErr := s.sendFormatted(ctx, user, text); sendErr != nil {
+		return s.skipForecast(user, sentAt, sendErr)
+	}
+
+	if hasDelta && isSwing(delta, user.SwingThreshold) {
+		if alertErr := s.sendAlert(user, weather.Condition(), fmt.Sprintf("⚠️Temperature alert: %v\nNow %v%v in %v", deltaText(delta), weather.Condition().Emoji(), weather.Condition().Description, weather.Name)); alertErr != nil {
+			log.Error().Err(alertErr).Msg("unable to send temperature alert")
+		}
+	}
+
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"forecastSentAt", sentAt},
+		{"observations", observations},
+		{"sunrise", weather.Sunrise().UTC()},
+		{"sunset", weather.Sunset().UTC()},
+	}}}, user.ID)
+}
+
+// skipForecast records failed attempt, so subscriber is not retried on every tick until the next delivery time
+func (s *Service) skipForecast(user db.User, sentAt time.Time, sendErr error) error {
+	if updateErr := s.DB.Update(bson.D{{"$set", bson.D{
+		{"forecastSentAt", sentAt},
+	}}}, user.ID); updateErr != nil {
+		return errors.Join(sendErr, updateErr)
+	}
+	return sendErr
+}
+
+// sendAlert sends alert with icon of weather condition. Alert is sent as text if there is no icon or it can't be sent
+func (s *Service) sendAlert(user db.User, condition weatherAPI.Weather, text string) error {
+	if condition.Icon != "" {
+		photoErr := s.Bot.SendPhotoURL(user.ChatID, condition.IconURL(), text)
+		if photoErr == nil {
+			return nil
+		}
+		log.Error().Err(photoErr).Msg("unable to send alert icon, sending text instead")
+	}
+
+	return s.API.SendResponse(user.ChatID, url.Values{
+		"chat_id": {strconv.Itoa(user.ChatID)},
+		"text":    {text},
+	})
+}
+
+// sendFormatted sends forecast text in user's format. Chart falls back to text if forecast is unavailable
+func (s *Service) sendFormatted(ctx context.Context, user db.User, text string) error {
+	if user.Format == utilities.FormatChart {
+		chartErr := s.sendChart(ctx, user, text)
+		if chartErr == nil {
+			return nil
+		}
+		log.Error().Err(chartErr).Msg("unable to send forecast chart, sending text instead")
+	}
+
+	return s.API.SendResponse(user.ChatID, url.Values{
+		"chat_id": {strconv.Itoa(user.ChatID)},
+		"text":    {text},
+	})
+}
+
+func (s *Service) sendChart(ctx context.Context, user db.User, text string) error {
+	forecast, forecastErr := s.Weather.Forecast(ctx, user)
+	if forecastErr != nil {
+		return forecastErr
+	}
+
+	chart, chartErr := weatherAPI.RenderChart(forecast)
+	if chartErr != nil {
+		return chartErr
+	}
+
+	//Forecast for several places may not fit into photo caption, so it is sent as a separate message
+	caption := fmt.Sprintf("%v\n%v", text, weatherAPI.FormatTrend(forecast))
+	if utf8.RuneCountInString(caption) <= captionLimit {
+		return s.Bot.SendPhoto(user.ChatID, chart, caption)
+	}
+	if photoErr := s.Bot.SendPhoto(user.ChatID, chart, weatherAPI.FormatTrend(forecast)); photoErr != nil {
+		return photoErr
+	}
+
+	return s.API.SendResponse(user.ChatID, url.Values{
+		"chat_id": {strconv.Itoa(user.ChatID)},
+		"text":    {text},
+	})
+}
+
+// newObservation returns today's observation of weather at user's place
+func newObservation(user db.User, weather weatherAPI.WeatherData, sentAt time.Time) db.Observation {
+	return db.Observation{
+		Date:  sentAt.Format(dateLayout),
+		Place: observationPlace(user),
+		Temp:  weather.Main.Temp,
+		Units: user.Units,
+	}
+}
+
+// findObservation returns stored observation of the place
+func findObservation(observations []db.Observation, place string) db.Observation {
+	for _, observation := range observations {
+		if observation.Place == place {
+			return observation
+		}
+	}
+	return db.Observation{}
+}
+
+// addObservation replaces observation of the place. Observations older than yesterday can't give a delta, so they are dropped
+func addObservation(observations []db.Observation, observation db.Observation, sentAt time.Time) []db.Observation {
+	yesterday := sentAt.AddDate(0, 0, -1).Format(dateLayout)
+	kept := []db.Observation{observation}
+	for _, previous := range observations {
+		if previous.Place != observation.Place && previous.Date >= yesterday {
+			kept = append(kept, previous)
+		}
+	}
+	return kept
+}
+
+// observationPlace returns a key of the place observation is stored for
+func observationPlace(user db.User) string {
+	if user.City != "" {
+		return user.City
+	}
+	return fmt.Sprintf("%.2f,%.2f", user.Location.Latitude, user.Location.Longitude)
+}
+
+// temperatureDelta returns rounded temperature change if previous observation was made yesterday at the same place in the same units
+func temperatureDelta(previous, current db.Observation) (int, bool) {
+	if previous.Date == "" || previous.Place != current.Place || previous.Units != current.Units {
+		return 0, false
+	}
+
+	currentDate, currentErr := time.Parse(dateLayout, current.Date)
+	if currentErr != nil {
+		return 0, false
+	}
+	if previous.Date != currentDate.AddDate(0, 0, -1).Format(dateLayout) {
+		return 0, false
+	}
+
+	return int(math.Round(current.Temp - previous.Temp)), true
+}
+
+func deltaText(delta int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("%v° warmer than yesterday", delta)
+	case delta < 0:
+		return fmt.Sprintf("%v° colder than yesterday", -delta)
+	default:
+		return "Same temperature as yesterday"
+	}
+}
+
+func isSwing(delta, threshold int) bool {
+	if threshold <= 0 {
+		return false
+	}
+	return delta >= threshold || -delta >= threshold
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_temperatureDelta(t *testing.T) {
+	today := db.Observation{Date: "2024-03-10", Place: "Kyiv", Temp: 4.6}
+
+	tests := []struct {
+		name      string
+		previous  db.Observation
+		want      int
+		wantDelta bool
+	}{
+		{
+			name:     "colder than yesterday",
+			previous: db.Observation{Date: "2024-03-09", Place: "Kyiv", Temp: 9.4},
+			want:     -5, wantDelta: true,
+		},
+		{
+			name:     "warmer than yesterday",
+			previous: db.Observation{Date: "2024-03-09", Place: "Kyiv", Temp: 1},
+			want:     4, wantDelta: true,
+		},
+		{
+			name:     "no previous observation",
+			previous: db.Observation{},
+		},
+		{
+			name:     "observation is older than yesterday",
+			previous: db.Observation{Date: "2024-03-07", Place: "Kyiv", Temp: 1},
+		},
+		{
+			name:     "place changed",
+			previous: db.Observation{Date: "2024-03-09", Place: "Lviv", Temp: 1},
+		},
+		{
+			name:     "units changed",
+			previous: db.Observation{Date: "2024-03-09", Place: "Kyiv", Temp: 34, Units: "imperial"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := temperatureDelta(tc.previous, today)
+			assert.Equal(t, tc.wantDelta, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func Test_addObservation(t *testing.T) {
+	sentAt := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	today := db.Observation{Date: "2024-03-10", Place: "Kyiv", Temp: 4.6}
+
+	tests := []struct {
+		name         string
+		observations []db.Observation
+		want         []db.Observation
+	}{
+		{
+			name: "first observation",
+			want: []db.Observation{today},
+		},
+		{
+			name:         "observation of the place is replaced",
+			observations: []db.Observation{{Date: "2024-03-09", Place: "Kyiv", Temp: 9.4}},
+			want:         []db.Observation{today},
+		},
+		{
+			name:         "observations of other places are kept",
+			observations: []db.Observation{{Date: "2024-03-09", Place: "Paris", Temp: 12}, {Date: "2024-03-10", Place: "Lviv", Temp: 3}},
+			want:         []db.Observation{today, {Date: "2024-03-09", Place: "Paris", Temp: 12}, {Date: "2024-03-10", Place: "Lviv", Temp: 3}},
+		},
+		{
+			name:         "observations older than yesterday are dropped",
+			observations: []db.Observation{{Date: "2024-03-08", Place: "Paris", Temp: 12}},
+			want:         []db.Observation{today},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, addObservation(tc.observations, today, sentAt))
+		})
+	}
+}
+
+func Test_deltaText(t *testing.T) {
+	assert.Equal(t, "5° colder than yesterday", deltaText(-5))
+	assert.Equal(t, "2° warmer than yesterday", deltaText(2))
+	assert.Equal(t, "Same temperature as yesterday", deltaText(0))
+	assert.True(t, isSwing(-5, 5))
+	assert.False(t, isSwing(4, 5))
+	assert.False(t, isSwing(10, 0))
+}
+
+func TestService_sendAlert(t *testing.T) {
+	controller := gomock.NewController(t)
+	api := mocks.NewTelegramService(controller)
+	bot := mocks.NewBotService(controller)
+	tgService := NewService(mocks.NewMongoStorage(controller), mocks.NewWeatherService(controller), api, bot)
+	user := db.User{ChatID: 358383178}
+	text := "⚠️Temperature alert: 5° colder than yesterday"
+	textReply := url.Values{"chat_id": {"358383178"}, "text": {text}}
+
+	tests := []struct {
+		name       string
+		condition  weatherAPI.Weather
+		setupMocks func()
+	}{
+		{
+			name:      "alert with condition icon",
+			condition: weatherAPI.Weather{ID: 500, Icon: "10d"},
+			setupMocks: func() {
+				bot.EXPECT().SendPhotoURL(user.ChatID, "https://openweathermap.org/img/wn/10d@2x.png", text).Return(nil)
+			},
+		},
+		{
+			name:      "icon can't be sent",
+			condition: weatherAPI.Weather{ID: 500, Icon: "10d"},
+			setupMocks: func() {
+				bot.EXPECT().SendPhotoURL(user.ChatID, gomock.Any(), text).Return(errors.New("bad request"))
+				api.EXPECT().SendResponse(user.ChatID, textReply).Return(nil)
+			},
+		},
+		{
+			name:      "condition without icon",
+			condition: weatherAPI.Weather{},
+			setupMocks: func() {
+				api.EXPECT().SendResponse(user.ChatID, textReply).Return(nil)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			assert.NoError(t, tgService.sendAlert(user, tc.condition, text))
+		})
+	}
+}
+
+func TestService_sendFormatted_chart(t *testing.T) {
+	controller := gomock.NewController(t)
+	api := mocks.NewTelegramService(controller)
+	bot := mocks.NewBotService(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(mocks.NewMongoStorage(controller), weather, api, bot)
+	user := db.User{ChatID: 358383178, Format: "chart"}
+	forecast := weatherAPI.ForecastData{List: []weatherAPI.ForecastItem{
+		{Dt: 1700000000, Main: weatherAPI.Main{Temp: 4}},
+		{Dt: 1700010800, Main: weatherAPI.Main{Temp: 7}},
+	}}
+	trend := weatherAPI.FormatTrend(forecast)
+	fitting := strings.Repeat("☀", captionLimit-utf8.RuneCountInString(trend)-1)
+	long := fitting + "☀"
+
+	tests := []struct {
+		name       string
+		text       string
+		setupMocks func()
+	}{
+		{
+			name: "caption at the limit",
+			text: fitting,
+			setupMocks: func() {
+				weather.EXPECT().Forecast(gomock.Any(), user).Return(forecast, nil)
+				bot.EXPECT().SendPhoto(user.ChatID, gomock.Any(), fitting+"\n"+trend).Return(nil)
+			},
+		},
+		{
+			name: "caption over the limit",
+			text: long,
+			setupMocks: func() {
+				weather.EXPECT().Forecast(gomock.Any(), user).Return(forecast, nil)
+				bot.EXPECT().SendPhoto(user.ChatID, gomock.Any(), trend).Return(nil)
+				api.EXPECT().SendResponse(user.ChatID, url.Values{"chat_id": {"358383178"}, "text": {long}}).Return(nil)
+			},
+		},
+		{
+			name: "empty forecast",
+			text: "Kyiv",
+			setupMocks: func() {
+				weather.EXPECT().Forecast(gomock.Any(), user).Return(weatherAPI.ForecastData{}, nil)
+				api.EXPECT().SendResponse(user.ChatID, url.Values{"chat_id": {"358383178"}, "text": {"Kyiv"}}).Return(nil)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			assert.NoError(t, tgService.sendFormatted(context.Background(), user, tc.text))
+		})
+	}
+}
+
+func TestService_sendForecast_commute(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	api := mocks.NewTelegramService(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, api, mocks.NewBotService(controller))
+	user := db.User{
+		ID:      primitive.ObjectID{1},
+		City:    "Kyiv",
+		ChatID:  358383178,
+		Commute: db.Commute{Departure: db.CommuteLeg{City: "Kyiv", Time: "08:30"}, Return: db.CommuteLeg{City: "Kyiv", Time: "18:00"}},
+	}
+	now := time.Date(2024, 1, 15, 7, 0, 10, 0, time.UTC)
+	nextTrigger := time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC)
+	step := func(day, hour int, temp float64, condition weatherAPI.Weather) weatherAPI.ForecastItem {
+		return weatherAPI.ForecastItem{
+			Dt:      int(time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC).Unix()),
+			Main:    weatherAPI.Main{Temp: temp},
+			Weather: []weatherAPI.Weather{condition},
+		}
+	}
+	clouds := weatherAPI.Weather{ID: 804, Description: "overcast clouds", Icon: "04d"}
+	rain := weatherAPI.Weather{ID: 500, Description: "light rain", Icon: "10d"}
+	current := weatherAPI.WeatherData{Weather: []weatherAPI.Weather{clouds}, Name: "Kyiv"}
+
+	weather.EXPECT().CurrentWeather(gomock.Any(), user).Return(current, nil)
+	weather.EXPECT().Forecast(gomock.Any(), placeUser(user, db.Place{City: "Kyiv"})).Return(weatherAPI.ForecastData{List: []weatherAPI.ForecastItem{
+		step(15, 9, 2.6, rain), step(15, 18, 1, clouds), step(16, 9, -4, clouds), step(16, 18, -6, clouds),
+	}}, nil).Times(2)
+	api.EXPECT().SendResponse(user.ChatID, url.Values{"chat_id": {"358383178"}, "text": {weatherAPI.FormatForecast(current) + "\n\n🚶Commute:\n" +
+		"Departure from Kyiv at 08:30: 🌧️light rain, 3°\n☔Rain expected, take an umbrella\n" +
+		"Return from Kyiv at 18:00: ☁️overcast clouds, 1°"}}).Return(nil)
+	storage.EXPECT().Update(gomock.Any(), user.ID).Return(nil)
+
+	assert.NoError(t, tgService.sendForecast(context.Background(), user, now, nextTrigger))
+}
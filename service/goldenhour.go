@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/utilities"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// goldenHourLength is how long golden hour lasts after sunrise and before sunset
+const goldenHourLength = 1 * time.Hour
+
+// nextGoldenHour returns start of the closest golden hour that has not started yet.
+// Sun times of the last forecast are shifted by days until they are in the future
+func nextGoldenHour(sunrise, sunset, currentTime time.Time) time.Time {
+	var next time.Time
+	for _, start := range []time.Time{sunrise, sunset.Add(-goldenHourLength)} {
+		for !start.After(currentTime) {
+			start = start.Add(24 * time.Hour)
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+
+	return next
+}
+
+// notifyGoldenHour sends a reminder if golden hour starts within user's reminder period
+func (s *Service) notifyGoldenHour(user db.User, currentTime time.Time) error {
+	if user.GoldenHourReminder <= 0 || user.Sunrise.IsZero() || user.Sunset.IsZero() {
+		return nil
+	}
+
+	start := nextGoldenHour(user.Sunrise, user.Sunset, currentTime)
+	if currentTime.Before(start.Add(-time.Duration(user.GoldenHourReminder)*time.Minute)) || !user.GoldenHourSentAt.Before(start) {
+		return nil
+	}
+
+	if sendErr := s.API.SendResponse(user.ChatID, url.Values{
+		"chat_id": {strconv.Itoa(user.ChatID)},
+		"text":    {fmt.Sprintf("📷Golden hour starts in %v minutes", int(start.Sub(currentTime).Round(time.Minute).Minutes()))},
+	}); sendErr != nil {
+		return sendErr
+	}
+
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"goldenHourSentAt", start},
+	}}}, user.ID)
+}
+
+func (s *Service) goldenHourUpdate(value string, user db.User, chatID int) (url.Values, error) {
+	reminder := 0
+	if value != utilities.Off {
+		parsed, parseErr := strconv.Atoi(value)
+		if parseErr != nil || parsed <= 0 || parsed > 12*60 {
+			return url.Values{
+				"chat_id": {strconv.Itoa(chatID)},
+				"text":    {"invalid reminder, try again.Example: /goldenhour 30 or /goldenhour off"},
+			}, nil
+		}
+		reminder = parsed
+	}
+
+	update := bson.D{{"goldenHourReminder", reminder}}
+	if reminder > 0 && (user.Sunrise.IsZero() || user.Sunset.IsZero()) {
+		weather, weatherErr := s.Weather.CurrentWeather(context.Background(), user)
+		if weatherErr != nil {
+			return url.Values{
+				"chat_id": {strconv.Itoa(chatID)},
+				"text":    {"unable to get sunrise and sunset for your location"},
+			}, weatherErr
+		}
+		update = append(update, bson.E{Key: "sunrise", Value: weather.Sunrise().UTC()}, bson.E{Key: "sunset", Value: weather.Sunset().UTC()})
+	}
+
+	updateErr := s.DB.Update(bson.D{{"$set", update}}, user.ID)
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	text := "Golden hour reminders disabled"
+	if reminder > 0 {
+		text = fmt.Sprintf("You will be reminded %v minutes before golden hour", reminder)
+	}
+	return url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"text":    {text},
+	}, nil
+}
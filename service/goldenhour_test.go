@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nextGoldenHour(t *testing.T) {
+	sunrise := time.Date(2024, 3, 10, 4, 30, 0, 0, time.UTC)
+	sunset := time.Date(2024, 3, 10, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		currentTime time.Time
+		want        time.Time
+	}{
+		{
+			name:        "before sunrise",
+			currentTime: time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC),
+			want:        sunrise,
+		},
+		{
+			name:        "before evening golden hour",
+			currentTime: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+			want:        time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "after sunset",
+			currentTime: time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC),
+			want:        sunrise.Add(24 * time.Hour),
+		},
+		{
+			name:        "sun times from previous days",
+			currentTime: time.Date(2024, 3, 12, 13, 0, 0, 0, time.UTC),
+			want:        time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, nextGoldenHour(sunrise, sunset, tc.currentTime))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+	"time"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MemberUpdate handles bot being added to or removed from a group or channel
+func (s *Service) MemberUpdate(update *telegram.Update, chatID int) (url.Values, error) {
+	member := update.MyChatMember
+	if member == nil || !member.Chat.IsGroup() {
+		return nil, nil
+	}
+
+	switch {
+	case member.NewChatMember.IsPresent() && !member.OldChatMember.IsPresent():
+		log.Info().Msgf("Bot added to %v ChatID:%v", member.Chat.Type, chatID)
+		return url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {utilities.GroupWelcome},
+		}, nil
+	case !member.NewChatMember.IsPresent():
+		log.Info().Msgf("Bot removed from %v ChatID:%v", member.Chat.Type, chatID)
+		return nil, s.deleteChatSubscription(chatID)
+	default:
+		return nil, nil
+	}
+}
+
+// deleteChatSubscription unsubscribes a chat bot can no longer post to
+func (s *Service) deleteChatSubscription(chatID int) error {
+	user, userErr := s.DB.GetUser(chatID)
+	if errors.Is(userErr, db.ErrNotFound) {
+		return nil
+	}
+	if userErr != nil {
+		return userErr
+	}
+
+	return s.deactivate(user, time.Now().UTC())
+}
+
+// chatMigrated moves subscription of a group to the supergroup it was upgraded to
+func (s *Service) chatMigrated(message telegram.Message) error {
+	user, userErr := s.DB.GetUser(message.Chat.ID)
+	if errors.Is(userErr, db.ErrNotFound) {
+		return nil
+	}
+	if userErr != nil {
+		return userErr
+	}
+
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"chatID", message.MigrateToChatID},
+	}}}, user.ID)
+}
+
+// groupAccess checks if sender can configure subscription of a group chat. Only messages addressed to bot are checked,
+// other chat messages are ignored. Returns reply for commands and buttons of members who can't configure it
+func (s *Service) groupAccess(message telegram.Message) (bool, url.Values, error) {
+	if message.Chat.Type == telegram.ChatChannel {
+		return true, nil, nil
+	}
+	//Anonymous admins send messages on behalf of the group
+	if message.SenderChat != nil && message.SenderChat.ID == message.Chat.ID {
+		return true, nil, nil
+	}
+	if !addressedToBot(message) {
+		return false, nil, nil
+	}
+
+	admin, adminErr := s.isChatAdmin(message.Chat.ID, message.From.ID)
+	if adminErr != nil || admin {
+		return admin, nil, adminErr
+	}
+
+	if !isCommandOrButton(message.Text) {
+		return false, nil, nil
+	}
+	return false, url.Values{
+		"chat_id": {strconv.Itoa(message.Chat.ID)},
+		"text":    {utilities.AdminsOnly},
+	}, nil
+}
+
+// addressedToBot reports if group message configures subscription: a command, a keyboard button, a shared location
+// or a reply to bot
+func addressedToBot(message telegram.Message) bool {
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From.IsBot {
+		return true
+	}
+	return isCommandOrButton(message.Text) || !utilities.IsLocationEmpty(message.Location)
+}
+
+// isCommandOrButton reports if text is a command or a text of reply keyboard button
+func isCommandOrButton(text string) bool {
+	switch text {
+	case utilities.Subscribe, utilities.Unsubscribe, utilities.Back, utilities.Cancel:
+		return true
+	default:
+		return strings.HasPrefix(text, "/")
+	}
+}
+
+// isChatAdmin reports if user is an admin of a group chat. Status is cached, so Bot API is called once per cache TTL
+func (s *Service) isChatAdmin(chatID, userID int) (bool, error) {
+	now := time.Now()
+	if admin, found := s.Admins.get(chatID, userID, now); found {
+		return admin, nil
+	}
+
+	member, memberErr := s.Bot.GetChatMember(chatID, userID)
+	if memberErr != nil {
+		return false, fmt.Errorf("unable to check chat admin: %w", memberErr)
+	}
+	s.Admins.set(chatID, userID, member.IsAdmin(), now)
+	return member.IsAdmin(), nil
+}
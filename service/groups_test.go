@@ -0,0 +1,120 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestService_MemberUpdate(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	group := telegram.Chat{ID: -100123, Type: telegram.ChatSupergroup}
+
+	tests := []struct {
+		name       string
+		old        string
+		new        string
+		want       string
+		setupMocks func()
+	}{
+		{
+			name:       "bot added",
+			old:        telegram.MemberLeft,
+			new:        telegram.MemberMember,
+			want:       "Hello! This is weather forecast bot. Chat admins can subscribe this chat to daily weather forecast with /start. Reply to my messages to provide time and city",
+			setupMocks: func() {},
+		},
+		{
+			name: "bot removed",
+			old:  telegram.MemberAdministrator,
+			new:  telegram.MemberKicked,
+			setupMocks: func() {
+				storage.EXPECT().GetUser(group.ID).Return(db.User{ID: primitive.ObjectID{1}}, nil)
+				storage.EXPECT().Update(deactivation{}, primitive.ObjectID{1}).Return(nil)
+			},
+		},
+		{
+			name: "bot removed from chat without subscription",
+			old:  telegram.MemberMember,
+			new:  telegram.MemberLeft,
+			setupMocks: func() {
+				storage.EXPECT().GetUser(group.ID).Return(db.User{}, db.ErrNotFound)
+			},
+		},
+		{
+			name:       "bot promoted",
+			old:        telegram.MemberMember,
+			new:        telegram.MemberAdministrator,
+			setupMocks: func() {},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			update := &telegram.Update{MyChatMember: &telegram.ChatMemberUpdated{
+				Chat:          group,
+				OldChatMember: telegram.ChatMember{Status: tc.old},
+				NewChatMember: telegram.ChatMember{Status: tc.new},
+			}}
+
+			got, err := tgService.MemberUpdate(update, group.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
+
+func TestService_AddSubscription_group(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	bot := mocks.NewBotService(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), bot)
+	group := telegram.Chat{ID: -100123, Type: telegram.ChatGroup}
+	member := telegram.From{ID: 42}
+
+	t.Run("member command is rejected", func(t *testing.T) {
+		bot.EXPECT().GetChatMember(group.ID, member.ID).Return(telegram.ChatMember{Status: telegram.MemberMember}, nil)
+
+		got, err := tgService.AddSubscription(&telegram.Update{Message: telegram.Message{Text: "/swing@weather_bot 5", Chat: group, From: member}}, group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Only chat admins can configure weather forecast for this chat", got.Get("text"))
+	})
+
+	t.Run("member status is cached", func(t *testing.T) {
+		got, err := tgService.AddSubscription(&telegram.Update{Message: telegram.Message{Text: utilities.Unsubscribe, Chat: group, From: member}}, group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Only chat admins can configure weather forecast for this chat", got.Get("text"))
+	})
+
+	t.Run("member reply to bot is ignored", func(t *testing.T) {
+		got, err := tgService.AddSubscription(&telegram.Update{Message: telegram.Message{Text: "Kyiv", Chat: group, From: member, ReplyToMessage: &telegram.Message{From: telegram.From{IsBot: true}}}}, group.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("chat message is ignored without member check", func(t *testing.T) {
+		other := telegram.From{ID: 43}
+		got, err := tgService.AddSubscription(&telegram.Update{Message: telegram.Message{Text: "nice weather", Chat: group, From: other}}, group.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("chat migrated to supergroup", func(t *testing.T) {
+		storage.EXPECT().GetUser(group.ID).Return(db.User{ID: primitive.ObjectID{1}}, nil)
+		storage.EXPECT().Update(bson.D{{"$set", bson.D{{"chatID", -100456}}}}, primitive.ObjectID{1}).Return(nil)
+
+		got, err := tgService.AddSubscription(&telegram.Update{Message: telegram.Message{Chat: group, MigrateToChatID: -100456}}, group.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
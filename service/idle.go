@@ -0,0 +1,43 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+	"time"
+)
+
+const resumeText = "Welcome back! Let's finish setting up your forecast."
+
+// stepExpired reports if user left onboarding step pending for longer than idle timeout
+func (s *Service) stepExpired(user db.User, state db.SubscriptionStatus, now time.Time) bool {
+	if state != db.Subscribed && state != db.TimeUpdated {
+		return false
+	}
+	if user.LastInteraction.IsZero() || s.Retention.IdleTimeout <= 0 {
+		return false
+	}
+	return now.Sub(user.LastInteraction) > s.Retention.IdleTimeout
+}
+
+// resumeOnboarding prompts for the pending step again instead of reading an unrelated message as its answer
+func resumeOnboarding(state db.SubscriptionStatus, chatID int) (url.Values, error) {
+	if state == db.Subscribed {
+		return timeStepReply(chatID, resumeText)
+	}
+	return locationStepReply(chatID, resumeText)
+}
+
+// isStepAnswer reports if message is still handled after step expired: commands, menu buttons and shared location
+func isStepAnswer(message telegram.Message) bool {
+	switch {
+	case !utilities.IsLocationEmpty(message.Location):
+		return true
+	case strings.HasPrefix(message.Text, "/"):
+		return true
+	default:
+		return message.Text == utilities.Unsubscribe || message.Text == utilities.Back || message.Text == utilities.Cancel
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestService_AddSubscription_idle(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+
+	tests := []struct {
+		name            string
+		text            string
+		state           db.SubscriptionStatus
+		lastInteraction time.Time
+		want            string
+		answered        bool
+	}{
+		{
+			name:            "expired time step is prompted again",
+			text:            "Kyiv",
+			state:           db.Subscribed,
+			lastInteraction: time.Now().Add(-48 * time.Hour),
+			want:            "Welcome back! Let's finish setting up your forecast.\nStep 1 of 2 ●○",
+		},
+		{
+			name:            "expired location step is prompted again",
+			text:            "08:00",
+			state:           db.TimeUpdated,
+			lastInteraction: time.Now().Add(-48 * time.Hour),
+			want:            "Welcome back! Let's finish setting up your forecast.\nStep 2 of 2 ●●",
+		},
+		{
+			name:            "command is handled after step expired",
+			text:            "/help",
+			state:           db.Subscribed,
+			lastInteraction: time.Now().Add(-48 * time.Hour),
+			want:            "You can update the time",
+		},
+		{
+			name:            "step is answered before timeout",
+			text:            "08:00",
+			state:           db.Subscribed,
+			lastInteraction: time.Now().Add(-time.Hour),
+			want:            "User time updated to 08:00 UTC.",
+			answered:        true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user := db.User{ID: primitive.ObjectID{1}, ChatID: 358383178, LastInteraction: tc.lastInteraction}
+			storage.EXPECT().GetUser(user.ChatID).Return(user, nil)
+			storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+			storage.EXPECT().UserSubscriptionStatus(user.ID).Return(int(tc.state), nil)
+			if tc.answered {
+				storage.EXPECT().Update(gomock.Any(), user.ID).Return(nil)
+			}
+
+			got, err := tgService.AddSubscription(requestBody(t, tc.text), 358383178)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(got.Get("text"), tc.want), got.Get("text"))
+		})
+	}
+}
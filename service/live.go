@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+	"time"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// liveLocationStep is a distance in degrees, about a kilometer, live location has to move to be stored
+const liveLocationStep = 0.01
+
+const locationText = "Enter city with /city New York or share location in the chat. Live location moves forecast location while you share it, lock to keep current location"
+
+// live location options user can choose
+var liveOptions = []string{utilities.LiveFollow, utilities.LiveLock}
+
+// LiveLocation follows live location shared in the chat unless user locked it. Live position is stored apart from
+// user's location, so forecast returns to it when sharing stops. In groups admin rights are checked when sharing starts,
+// then only edits of the same sender are followed
+func (s *Service) LiveLocation(update *telegram.Update, chatID int) (url.Values, error) {
+	message := update.EditedMessage
+	if message == nil || utilities.IsLocationEmpty(message.Location) {
+		return nil, nil
+	}
+	user, userErr := s.DB.GetUser(chatID)
+	if errors.Is(userErr, db.ErrNotFound) {
+		return nil, nil
+	}
+	if userErr != nil {
+		return nil, userErr
+	}
+	if user.Inactive || user.LocationLocked || user.SubscriptionStatus != int(db.LocationProvided) {
+		return nil, nil
+	}
+	if message.Chat.IsGroup() && user.LiveLocation.SharedBy != sender(*message, chatID) {
+		return nil, nil
+	}
+
+	//Last edit of live location has no live period
+	if message.Location.LivePeriod == 0 {
+		if user.LiveLocation.Until.IsZero() {
+			return nil, nil
+		}
+		log.Debug().Msgf("Live location of ChatID:%v stopped", chatID)
+		return nil, s.updateLiveLocation(user, db.LiveLocation{})
+	}
+
+	live := liveLocation(*message)
+	if !moved(user.LiveLocation, live) {
+		return nil, nil
+	}
+	log.Debug().Msgf("Live location of ChatID:%v moved", chatID)
+	return nil, s.updateLiveLocation(user, live)
+}
+
+// liveStart handles live location shared in the chat by subscribed user
+func (s *Service) liveStart(message telegram.Message, user db.User, chatID int) (url.Values, error) {
+	if user.LocationLocked {
+		return textReply(chatID, "Forecast location is locked, live location is ignored. Follow it with /location follow"), nil
+	}
+
+	if updateErr := s.updateLiveLocation(user, liveLocation(message)); updateErr != nil {
+		return nil, updateErr
+	}
+	return textReply(chatID, "Forecast will follow your live location while you share it"), nil
+}
+
+func (s *Service) updateLiveLocation(user db.User, live db.LiveLocation) error {
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"liveLocation", live},
+	}}}, user.ID)
+}
+
+// liveLocation returns live position of the message shared until its live period ends. Position shared without
+// end is followed for a day since the last update
+func liveLocation(message telegram.Message) db.LiveLocation {
+	until := time.Unix(int64(message.Date), 0).Add(time.Duration(message.Location.LivePeriod) * time.Second)
+	if message.Location.LivePeriod >= telegram.LivePeriodForever {
+		until = time.Unix(int64(message.EditDate), 0).Add(24 * time.Hour)
+	}
+
+	return db.LiveLocation{
+		Location: db.Location{Latitude: message.Location.Latitude, Longitude: message.Location.Longitude},
+		Until:    until.UTC(),
+		SharedBy: sender(message, message.Chat.ID),
+	}
+}
+
+// moved reports if live location is far enough from stored one, so every small move is not written
+func moved(previous, current db.LiveLocation) bool {
+	if !previous.Until.Equal(current.Until) {
+		return true
+	}
+	return math.Abs(previous.Location.Latitude-current.Location.Latitude) >= liveLocationStep ||
+		math.Abs(previous.Location.Longitude-current.Location.Longitude) >= liveLocationStep
+}
+
+// following returns user with live location as location while it is shared
+func following(user db.User, at time.Time) db.User {
+	if user.LocationLocked || !at.Before(user.LiveLocation.Until) {
+		return user
+	}
+
+	user.City = ""
+	user.Place = ""
+	user.Location = user.LiveLocation.Location
+	return user
+}
+
+// locationCommand handles /location. Shows live location menu or locks and unlocks location given after the command
+func (s *Service) locationCommand(value string, user db.User, chatID int) (url.Values, error) {
+	if value == "" {
+		return menuReply(chatID, locationText, utilities.ChoiceMenu(utilities.LocationCallback, liveOptions, liveOption(user)))
+	}
+
+	step, stepErr := s.locationCallback(value, user, db.SubscriptionStatus(user.SubscriptionStatus), chatID)
+	if stepErr != nil || step.Set == nil {
+		return step.Reply, stepErr
+	}
+	if updateErr := s.DB.Update(bson.D{{"$set", step.Set}}, user.ID); updateErr != nil {
+		return nil, updateErr
+	}
+	return step.Reply, nil
+}
+
+func (s *Service) locationCallback(value string, user db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	if !isOption(liveOptions, value) {
+		reply, replyErr := menuReply(chatID, "invalid option, try again.Example: /location lock or /location follow", utilities.ChoiceMenu(utilities.LocationCallback, liveOptions, liveOption(user)))
+		return Step{Reply: reply}, replyErr
+	}
+
+	text := "Forecast location follows your live location while you share it"
+	if value == utilities.LiveLock {
+		text = "Forecast location is locked, live location is ignored"
+	}
+	reply, replyErr := menuReply(chatID, text, backToSettings(utilities.ChoiceMenu(utilities.LocationCallback, liveOptions, value)))
+	if replyErr != nil {
+		return Step{}, replyErr
+	}
+	return Step{Reply: reply, Set: bson.D{{"locationLocked", value == utilities.LiveLock}}}, nil
+}
+
+func liveOption(user db.User) string {
+	if user.LocationLocked {
+		return utilities.LiveLock
+	}
+	return utilities.LiveFollow
+}
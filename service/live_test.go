@@ -0,0 +1,231 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"subscriptionbot/telegram"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestService_LiveLocation(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	private := telegram.Chat{ID: 358383178, Type: telegram.ChatPrivate}
+	started := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	until := started.Add(time.Hour)
+	shared := db.LiveLocation{Location: db.Location{Latitude: 50.45, Longitude: 30.52}, Until: until, SharedBy: private.ID}
+	moving := telegram.Location{Latitude: 50.47, Longitude: 30.52, LivePeriod: 3600}
+
+	tests := []struct {
+		name     string
+		user     db.User
+		location telegram.Location
+		want     *db.LiveLocation
+	}{
+		{
+			name:     "live location is followed",
+			user:     db.User{City: "Kyiv", LiveLocation: shared, SubscriptionStatus: int(db.LocationProvided)},
+			location: moving,
+			want:     &db.LiveLocation{Location: db.Location{Latitude: 50.47, Longitude: 30.52}, Until: until, SharedBy: private.ID},
+		},
+		{
+			name:     "small move is not stored",
+			user:     db.User{City: "Kyiv", LiveLocation: shared, SubscriptionStatus: int(db.LocationProvided)},
+			location: telegram.Location{Latitude: 50.451, Longitude: 30.521, LivePeriod: 3600},
+		},
+		{
+			name:     "sharing stopped",
+			user:     db.User{City: "Kyiv", LiveLocation: shared, SubscriptionStatus: int(db.LocationProvided)},
+			location: telegram.Location{Latitude: 50.47, Longitude: 30.52},
+			want:     &db.LiveLocation{},
+		},
+		{
+			name:     "locked location",
+			user:     db.User{City: "Kyiv", LocationLocked: true, SubscriptionStatus: int(db.LocationProvided)},
+			location: moving,
+		},
+		{
+			name:     "unsubscribed user",
+			user:     db.User{City: "Kyiv", Inactive: true, SubscriptionStatus: int(db.LocationProvided)},
+			location: moving,
+		},
+		{
+			name:     "onboarding is not finished",
+			user:     db.User{SubscriptionStatus: int(db.TimeUpdated)},
+			location: moving,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.user.ID = primitive.ObjectID{1}
+			storage.EXPECT().GetUser(private.ID).Return(tc.user, nil)
+			if tc.want != nil {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"liveLocation", *tc.want}}}}, tc.user.ID).Return(nil)
+			}
+
+			message := &telegram.Message{Chat: private, Location: tc.location, Date: int(started.Unix()), EditDate: int(started.Add(10 * time.Minute).Unix())}
+			got, err := tgService.LiveLocation(&telegram.Update{EditedMessage: message}, private.ID)
+			require.NoError(t, err)
+			assert.Empty(t, got.Get("text"))
+		})
+	}
+}
+
+func TestService_LiveLocation_group(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	group := telegram.Chat{ID: -100123, Type: telegram.ChatSupergroup}
+	admin := telegram.From{ID: 42}
+	started := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	user := db.User{
+		ID:                 primitive.ObjectID{1},
+		City:               "Kyiv",
+		SubscriptionStatus: int(db.LocationProvided),
+		LiveLocation:       db.LiveLocation{Location: db.Location{Latitude: 50.45, Longitude: 30.52}, Until: started.Add(time.Hour), SharedBy: admin.ID},
+	}
+	moving := telegram.Location{Latitude: 50.47, Longitude: 30.52, LivePeriod: 3600}
+
+	tests := []struct {
+		name string
+		from telegram.From
+		want *db.LiveLocation
+	}{
+		{
+			name: "edit of admin who started sharing",
+			from: admin,
+			want: &db.LiveLocation{Location: db.Location{Latitude: 50.47, Longitude: 30.52}, Until: started.Add(time.Hour), SharedBy: admin.ID},
+		},
+		{
+			name: "edit of other member",
+			from: telegram.From{ID: 43},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			storage.EXPECT().GetUser(group.ID).Return(user, nil)
+			if tc.want != nil {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"liveLocation", *tc.want}}}}, user.ID).Return(nil)
+			}
+
+			message := &telegram.Message{Chat: group, From: tc.from, Location: moving, Date: int(started.Unix()), EditDate: int(started.Add(10 * time.Minute).Unix())}
+			_, err := tgService.LiveLocation(&telegram.Update{EditedMessage: message}, group.ID)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_AddSubscription_liveLocation(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}, ChatID: 358383178, City: "Kyiv"}
+	started := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	message := telegram.Message{
+		Chat:     telegram.Chat{ID: user.ChatID, Type: telegram.ChatPrivate},
+		Date:     int(started.Unix()),
+		Location: telegram.Location{Latitude: 48.85, Longitude: 2.35, LivePeriod: 900},
+	}
+
+	storage.EXPECT().GetUser(user.ChatID).Return(user, nil)
+	storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+	storage.EXPECT().UserSubscriptionStatus(user.ID).Return(int(db.LocationProvided), nil)
+	storage.EXPECT().Update(bson.D{{"$set", bson.D{{"liveLocation", db.LiveLocation{
+		Location: db.Location{Latitude: 48.85, Longitude: 2.35},
+		Until:    started.Add(15 * time.Minute),
+		SharedBy: user.ChatID,
+	}}}}}, user.ID).Return(nil)
+
+	got, err := tgService.AddSubscription(&telegram.Update{Message: message}, user.ChatID)
+	require.NoError(t, err)
+	assert.Equal(t, "Forecast will follow your live location while you share it", got.Get("text"))
+}
+
+func TestFollowing(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	live := db.LiveLocation{Location: db.Location{Latitude: 48.85, Longitude: 2.35}, Until: now.Add(time.Hour)}
+
+	tests := []struct {
+		name string
+		user db.User
+		at   time.Time
+		want db.User
+	}{
+		{
+			name: "live location is shared",
+			user: db.User{City: "Kyiv", LiveLocation: live},
+			at:   now,
+			want: db.User{Location: live.Location, LiveLocation: live},
+		},
+		{
+			name: "sharing ended",
+			user: db.User{City: "Kyiv", LiveLocation: live},
+			at:   now.Add(2 * time.Hour),
+			want: db.User{City: "Kyiv", LiveLocation: live},
+		},
+		{
+			name: "locked location",
+			user: db.User{City: "Kyiv", LiveLocation: live, LocationLocked: true},
+			at:   now,
+			want: db.User{City: "Kyiv", LiveLocation: live, LocationLocked: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, following(tc.user, tc.at))
+		})
+	}
+}
+
+func TestService_locationCommand(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}}
+
+	tests := []struct {
+		name       string
+		args       string
+		want       string
+		setupMocks func()
+	}{
+		{name: "menu", args: "", want: locationText},
+		{
+			name: "lock",
+			args: "lock",
+			want: "Forecast location is locked, live location is ignored",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"locationLocked", true}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name: "follow",
+			args: "follow",
+			want: "Forecast location follows your live location while you share it",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"locationLocked", false}}}}, user.ID).Return(nil)
+			},
+		},
+		{name: "invalid option", args: "stay", want: "invalid option, try again.Example: /location lock or /location follow"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.setupMocks != nil {
+				tc.setupMocks()
+			}
+
+			got, err := tgService.locationCommand(tc.args, user, 358383178)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+			assert.NotEmpty(t, got.Get("reply_markup"))
+		})
+	}
+}
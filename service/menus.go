@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+	"time"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// units user can choose
+var unitsOptions = []string{utilities.UnitsMetric, utilities.UnitsImperial}
+
+// formats user can choose
+var formatOptions = []string{utilities.FormatText, utilities.FormatChart}
+
+// callbackDataLimit is max length of inline button data in bytes
+const callbackDataLimit = 64
+
+// Callback handles presses of inline keyboard buttons. Message with the menu is edited in place with the reply
+func (s *Service) Callback(update *telegram.Update, chatID int) (url.Values, error) {
+	query := update.CallbackQuery
+	reply, notice, replyErr := s.callbackReply(query, chatID)
+	if replyErr != nil {
+		log.Error().Err(replyErr).Msgf("unable to handle callback %v for ChatID:%v", query.Data, chatID)
+		notice = "Something went wrong, try again"
+	}
+
+	if answerErr := s.Bot.AnswerCallbackQuery(query.ID, notice); answerErr != nil {
+		log.Error().Err(answerErr).Msgf("unable to answer callback for ChatID:%v", chatID)
+	}
+	if replyErr != nil || reply.Get("text") == "" {
+		return nil, nil
+	}
+
+	if editErr := s.Bot.EditMessageText(chatID, query.Message.MessageID, reply.Get("text"), reply.Get("reply_markup")); editErr != nil {
+		log.Error().Err(editErr).Msgf("unable to edit menu for ChatID:%v", chatID)
+	}
+	return nil, nil
+}
+
+// callbackReply returns reply for pressed button or a notice if the button can't be used
+func (s *Service) callbackReply(query *telegram.CallbackQuery, chatID int) (url.Values, string, error) {
+	if query.Message.Chat.IsGroup() && query.Message.Chat.Type != telegram.ChatChannel {
+		admin, adminErr := s.isChatAdmin(chatID, query.From.ID)
+		if adminErr != nil {
+			return nil, "", adminErr
+		}
+		if !admin {
+			return nil, utilities.AdminsOnly, nil
+		}
+	}
+
+	user, userErr := s.DB.GetUser(chatID)
+	if errors.Is(userErr, db.ErrNotFound) {
+		return nil, "Please subscribe to continue", nil
+	}
+	if userErr != nil {
+		return nil, "", userErr
+	}
+	if touchErr := s.DB.Touch(user.ID, time.Now().UTC()); touchErr != nil {
+		log.Error().Err(touchErr).Msgf("unable to store last interaction of ChatID:%v", chatID)
+	}
+
+	if action, _ := utilities.ParseCallbackData(query.Data); user.Inactive && action != utilities.UndoCallback {
+		return nil, "Please subscribe to continue", nil
+	}
+
+	reply, replyErr := subscriptionFlow.HandleCallback(s, query.Data, user, db.SubscriptionStatus(user.SubscriptionStatus), chatID)
+	switch {
+	case errors.Is(replyErr, ErrUnknownCallback):
+		return nil, "This button is no longer available", nil
+	case errors.Is(replyErr, ErrNotConfigurable):
+		return nil, "Please subscribe to continue", nil
+	}
+	return reply, "", replyErr
+}
+
+// unitsCommand handles /units. Shows units menu or sets units given after the command
+func (s *Service) unitsCommand(units string, user db.User, chatID int) (url.Values, error) {
+	if units == "" {
+		return menuReply(chatID, "Choose units for weather forecast", utilities.ChoiceMenu(utilities.UnitsCallback, unitsOptions, user.Units))
+	}
+
+	step, stepErr := s.unitsCallback(units, user, db.SubscriptionStatus(user.SubscriptionStatus), chatID)
+	if stepErr != nil || step.Set == nil {
+		return step.Reply, stepErr
+	}
+	if updateErr := s.DB.Update(bson.D{{"$set", step.Set}}, user.ID); updateErr != nil {
+		return nil, updateErr
+	}
+	return step.Reply, nil
+}
+
+func (s *Service) unitsCallback(units string, user db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	if !isOption(unitsOptions, units) {
+		reply, replyErr := menuReply(chatID, "invalid units, try again.Example: /units metric or /units imperial", utilities.ChoiceMenu(utilities.UnitsCallback, unitsOptions, user.Units))
+		return Step{Reply: reply}, replyErr
+	}
+
+	reply, replyErr := menuReply(chatID, fmt.Sprintf("Units updated to %v", units), backToSettings(utilities.ChoiceMenu(utilities.UnitsCallback, unitsOptions, units)))
+	if replyErr != nil {
+		return Step{}, replyErr
+	}
+	return Step{Reply: reply, Set: bson.D{{"units", units}}}, nil
+}
+
+func (s *Service) formatCallback(format string, user db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	if !isOption(formatOptions, format) {
+		reply, replyErr := menuReply(chatID, "invalid format, try again", utilities.ChoiceMenu(utilities.FormatCallback, formatOptions, user.Format))
+		return Step{Reply: reply}, replyErr
+	}
+
+	reply, replyErr := menuReply(chatID, fmt.Sprintf("Forecast format updated to %v", format), backToSettings(utilities.ChoiceMenu(utilities.FormatCallback, formatOptions, format)))
+	if replyErr != nil {
+		return Step{}, replyErr
+	}
+	return Step{Reply: reply, Set: bson.D{{"format", format}}}, nil
+}
+
+// cityCallback sets city chosen from the menu of current and saved cities
+func (s *Service) cityCallback(city string, user db.User, state db.SubscriptionStatus, chatID int) (Step, error) {
+	if !isOption(cityChoices(user), city) {
+		return Step{Reply: textReply(chatID, "This city is no longer saved. Enter it with /city New York")}, nil
+	}
+
+	step, stepErr := s.cityCommand(city, user, state, chatID)
+	if stepErr != nil || state != db.LocationProvided {
+		return step, stepErr
+	}
+
+	reply, replyErr := menuReply(chatID, fmt.Sprintf("City updated to %v", city), utilities.ChoiceMenu(utilities.CityCallback, cityChoices(user), city))
+	if replyErr != nil {
+		return Step{}, replyErr
+	}
+	step.Reply = reply
+	return step, nil
+}
+
+// cityChoices returns current city and cities of saved places that fit into button data
+func cityChoices(user db.User) []string {
+	var cities []string
+	for _, city := range append([]string{user.City}, placeCities(user.Places)...) {
+		if city == "" || isOption(cities, city) || len(utilities.CallbackData(utilities.CityCallback, city)) > callbackDataLimit {
+			continue
+		}
+		cities = append(cities, city)
+	}
+	return cities
+}
+
+func placeCities(places []db.Place) []string {
+	cities := make([]string, 0, len(places))
+	for _, place := range places {
+		cities = append(cities, place.City)
+	}
+	return cities
+}
+
+// menuReply returns reply with inline keyboard
+func menuReply(chatID int, text string, keyboard utilities.InlineKeyboardMarkup) (url.Values, error) {
+	jsonData, jsonErr := utilities.InlineMarshal(keyboard)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	return url.Values{
+		"chat_id":      {strconv.Itoa(chatID)},
+		"text":         {text},
+		"reply_markup": {string(jsonData)},
+	}, nil
+}
+
+func isOption(options []string, value string) bool {
+	for _, option := range options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
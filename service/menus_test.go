@@ -0,0 +1,155 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"subscriptionbot/telegram"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func callbackUpdate(chat telegram.Chat, data string) *telegram.Update {
+	return &telegram.Update{CallbackQuery: &telegram.CallbackQuery{
+		ID:      "callback",
+		From:    telegram.From{ID: 42},
+		Message: &telegram.Message{MessageID: 7, Chat: chat},
+		Data:    data,
+	}}
+}
+
+func TestService_Callback(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	bot := mocks.NewBotService(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), bot)
+
+	private := telegram.Chat{ID: 358383178, Type: telegram.ChatPrivate}
+	group := telegram.Chat{ID: -100123, Type: telegram.ChatSupergroup}
+	user := db.User{ID: primitive.ObjectID{1}, SubscriptionStatus: int(db.LocationProvided), Units: "metric"}
+
+	tests := []struct {
+		name       string
+		chat       telegram.Chat
+		data       string
+		setupMocks func()
+	}{
+		{
+			name: "units changed",
+			chat: private,
+			data: "units:imperial",
+			setupMocks: func() {
+				storage.EXPECT().GetUser(private.ID).Return(user, nil)
+				storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"units", "imperial"}}}}, user.ID).Return(nil)
+				bot.EXPECT().AnswerCallbackQuery("callback", "").Return(nil)
+				bot.EXPECT().EditMessageText(private.ID, 7, "Units updated to imperial",
+					`{"inline_keyboard":[[{"text":"metric","callback_data":"units:metric"},{"text":"✅ imperial","callback_data":"units:imperial"}],[{"text":"« Settings","callback_data":"settings:"}]]}`).Return(nil)
+			},
+		},
+		{
+			name: "format changed",
+			chat: private,
+			data: "format:chart",
+			setupMocks: func() {
+				storage.EXPECT().GetUser(private.ID).Return(user, nil)
+				storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"format", "chart"}}}}, user.ID).Return(nil)
+				bot.EXPECT().AnswerCallbackQuery("callback", "").Return(nil)
+				bot.EXPECT().EditMessageText(private.ID, 7, "Forecast format updated to chart", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "unknown button",
+			chat: private,
+			data: "language:en",
+			setupMocks: func() {
+				storage.EXPECT().GetUser(private.ID).Return(user, nil)
+				storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+				bot.EXPECT().AnswerCallbackQuery("callback", "This button is no longer available").Return(nil)
+			},
+		},
+		{
+			name: "not subscribed",
+			chat: private,
+			data: "units:imperial",
+			setupMocks: func() {
+				storage.EXPECT().GetUser(private.ID).Return(db.User{}, db.ErrNotFound)
+				bot.EXPECT().AnswerCallbackQuery("callback", "Please subscribe to continue").Return(nil)
+			},
+		},
+		{
+			name: "settings before subscription is finished",
+			chat: private,
+			data: "units:imperial",
+			setupMocks: func() {
+				onboarding := user
+				onboarding.SubscriptionStatus = int(db.TimeUpdated)
+				storage.EXPECT().GetUser(private.ID).Return(onboarding, nil)
+				storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+				bot.EXPECT().AnswerCallbackQuery("callback", "Please subscribe to continue").Return(nil)
+			},
+		},
+		{
+			name: "group member",
+			chat: group,
+			data: "units:imperial",
+			setupMocks: func() {
+				bot.EXPECT().GetChatMember(group.ID, 42).Return(telegram.ChatMember{Status: telegram.MemberMember}, nil)
+				bot.EXPECT().AnswerCallbackQuery("callback", "Only chat admins can configure weather forecast for this chat").Return(nil)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			got, err := tgService.Callback(callbackUpdate(tc.chat, tc.data), tc.chat.ID)
+			require.NoError(t, err)
+			assert.Empty(t, got)
+		})
+	}
+}
+
+func TestService_cityCallback(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{
+		ID:     primitive.ObjectID{1},
+		City:   "Kyiv",
+		Places: []db.Place{{Name: "Parents", City: "Lviv"}, {Name: "Home", City: "Kyiv"}, {Name: "Car"}},
+	}
+
+	t.Run("city choices", func(t *testing.T) {
+		assert.Equal(t, []string{"Kyiv", "Lviv"}, cityChoices(user))
+	})
+
+	t.Run("choose saved city", func(t *testing.T) {
+		weather.EXPECT().WeatherRequest(gomock.Any(), gomock.Any()).Return(nil, nil)
+		storage.EXPECT().Update(bson.D{{"$set", bson.D{{"city", "Lviv"}}}}, user.ID).Return(nil)
+
+		got, err := subscriptionFlow.HandleCallback(tgService, "city:Lviv", user, db.LocationProvided, 358383178)
+		require.NoError(t, err)
+		assert.Equal(t, "City updated to Lviv", got.Get("text"))
+		assert.Contains(t, got.Get("reply_markup"), "✅ Lviv")
+	})
+
+	t.Run("city is no longer saved", func(t *testing.T) {
+		got, err := subscriptionFlow.HandleCallback(tgService, "city:Odesa", user, db.LocationProvided, 358383178)
+		require.NoError(t, err)
+		assert.Equal(t, "This city is no longer saved. Enter it with /city New York", got.Get("text"))
+	})
+
+	t.Run("city command shows choices", func(t *testing.T) {
+		got, err := tgService.cityCommand("", user, db.LocationProvided, 358383178)
+		require.NoError(t, err)
+		assert.Equal(t, "Choose city or enter it after the command. Example: /city New York", got.Reply.Get("text"))
+		assert.Contains(t, got.Reply.Get("reply_markup"), "city:Lviv")
+	})
+}
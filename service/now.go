@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	weatherAPI "subscriptionbot/weather"
+	"time"
+)
+
+// nowCommand handles /now and /now <city>. Sends current forecast for saved location or given city.
+// Requests are limited per user by the state machine
+func (s *Service) nowCommand(city string, user db.User, chatID int) (url.Values, error) {
+	target := user
+	target.ChatID = chatID
+	if city != "" {
+		target.City = city
+		target.Location = db.Location{}
+	}
+
+	ctx := context.Background()
+	weather, weatherErr := s.Weather.CurrentWeather(ctx, target)
+	switch {
+	case errors.Is(weatherErr, weatherAPI.ErrNoLocation):
+		return nowReply(chatID, "please set city first or enter it after the command\nExample: /now New York"), nil
+	case errors.Is(weatherErr, weatherAPI.ErrNotFound):
+		return nowReply(chatID, fmt.Sprintf("unable to find weather for %v", city)), nil
+	case weatherErr != nil:
+		return nowReply(chatID, "weather is unavailable right now, try again later"), weatherErr
+	}
+
+	return nil, s.sendFormatted(ctx, target, weatherAPI.FormatForecast(weather))
+}
+
+// rateLimited returns reply if sender of the message has sent too many requests
+func (s *Service) rateLimited(message telegram.Message, chatID int) (url.Values, bool) {
+	allowed, wait := s.Limiter.Allow(sender(message, chatID), time.Now())
+	if allowed {
+		return nil, false
+	}
+	return nowReply(chatID, fmt.Sprintf("Too many requests. Try again in %v", wait.Round(time.Second))), true
+}
+
+// sender returns ID of user who sent the message. Channel posts and anonymous admins send on behalf of a chat
+func sender(message telegram.Message, chatID int) int {
+	switch {
+	case message.SenderChat != nil:
+		return message.SenderChat.ID
+	case message.From.ID != 0:
+		return message.From.ID
+	default:
+		return chatID
+	}
+}
+
+func nowReply(chatID int, text string) url.Values {
+	return url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"text":    {text},
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"net/url"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"subscriptionbot/telegram"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestService_nowCommand(t *testing.T) {
+	controller := gomock.NewController(t)
+	weather := mocks.NewWeatherService(controller)
+	telegramService := mocks.NewTelegramService(controller)
+	tgService := NewService(mocks.NewMongoStorage(controller), weather, telegramService, mocks.NewBotService(controller))
+	tgService.Limiter = NewRateLimiter(RateLimitConfig{Limit: 3, Window: time.Minute})
+
+	user := db.User{ID: primitive.ObjectID{1}, City: "Kyiv", ChatID: 358383178}
+	kyiv := weatherAPI.WeatherData{Name: "Kyiv"}
+
+	tests := []struct {
+		name       string
+		city       string
+		want       string
+		setupMocks func()
+	}{
+		{
+			name: "saved city",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), user).Return(kyiv, nil)
+				telegramService.EXPECT().SendResponse(user.ChatID, url.Values{
+					"chat_id": {"358383178"},
+					"text":    {weatherAPI.FormatForecast(kyiv)},
+				}).Return(nil)
+			},
+		},
+		{
+			name: "unknown city",
+			city: "Atlantis",
+			want: "unable to find weather for Atlantis",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), placeUser(user, db.Place{City: "Atlantis"})).Return(weatherAPI.WeatherData{}, weatherAPI.ErrNotFound)
+			},
+		},
+		{
+			name: "no saved location",
+			want: "please set city first or enter it after the command\nExample: /now New York",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), user).Return(weatherAPI.WeatherData{}, weatherAPI.ErrNoLocation)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			got, err := tgService.nowCommand(tc.city, user, user.ChatID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
+
+func TestService_rateLimited(t *testing.T) {
+	controller := gomock.NewController(t)
+	tgService := NewService(mocks.NewMongoStorage(controller), mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	tgService.Limiter = NewRateLimiter(RateLimitConfig{Limit: 1, Window: time.Minute})
+	group := telegram.Chat{ID: -100123, Type: telegram.ChatSupergroup}
+
+	tests := []struct {
+		name    string
+		message telegram.Message
+		want    string
+	}{
+		{name: "first user", message: telegram.Message{Chat: group, From: telegram.From{ID: 1}}},
+		{name: "first user again", message: telegram.Message{Chat: group, From: telegram.From{ID: 1}}, want: "Too many requests. Try again in 1m0s"},
+		{name: "other user of the same chat", message: telegram.Message{Chat: group, From: telegram.From{ID: 2}}},
+		{name: "anonymous admin", message: telegram.Message{Chat: group, From: telegram.From{ID: 1087968824}, SenderChat: &group}},
+		{name: "anonymous admin again", message: telegram.Message{Chat: group, From: telegram.From{ID: 1087968824}, SenderChat: &group}, want: "Too many requests. Try again in 1m0s"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, limited := tgService.rateLimited(tc.message, group.ID)
+			assert.Equal(t, tc.want != "", limited)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/utilities"
+)
+
+// onboarding steps after subscribe: time and location
+const onboardingSteps = 2
+
+// onboarding actions of inline buttons
+const (
+	onboardingSkip   = "skip"
+	onboardingCancel = "cancel"
+)
+
+// onboardingCallback handles Skip and Cancel buttons of time step
+func (s *Service) onboardingCallback(action string, user db.User, state db.SubscriptionStatus, chatID int) (Step, error) {
+	if state != db.Subscribed {
+		return Step{Reply: textReply(chatID, "This step is already completed")}, nil
+	}
+
+	switch action {
+	case onboardingSkip:
+		follow, followErr := locationStepReply(chatID, "")
+		return Step{Reply: textReply(chatID, fmt.Sprintf("Default time %v UTC kept", user.UserTime)), Next: db.TimeUpdated, Follow: follow}, followErr
+	case onboardingCancel:
+		return Step{Reply: textReply(chatID, cancelledText), Next: db.NewUser}, nil
+	default:
+		return Step{Reply: textReply(chatID, "This button is no longer available")}, nil
+	}
+}
+
+const cancelledText = "Subscription cancelled. Press Subscribe to start again"
+
+// cancelOnboarding returns flow to the start with subscribe menu
+func cancelOnboarding(chatID int) (Step, error) {
+	jsonData, jsonErr := utilities.ButtonMarshal(utilities.MenuButtons)
+	if jsonErr != nil {
+		return Step{}, fmt.Errorf("error marshaling JSON: %w", jsonErr)
+	}
+
+	return Step{
+		Reply: url.Values{
+			"chat_id":      {strconv.Itoa(chatID)},
+			"text":         {cancelledText},
+			"reply_markup": {string(jsonData)},
+		},
+		Next: db.NewUser,
+	}, nil
+}
+
+// timeStepReply asks for time of daily forecast with time picker, skip and cancel buttons
+func timeStepReply(chatID int, intro string) (url.Values, error) {
+	keyboard := utilities.HourPicker()
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []utilities.InlineKeyboardButton{
+		{Text: "⏭ " + utilities.Skip, CallbackData: utilities.CallbackData(utilities.OnboardingCallback, onboardingSkip)},
+		{Text: "✖️ " + utilities.Cancel, CallbackData: utilities.CallbackData(utilities.OnboardingCallback, onboardingCancel)},
+	})
+
+	text := fmt.Sprintf("%v\n%v. Please pick hour below or enter time for weather forecast every day.Example: /time 15:00 or 7am.\nTime when subscribed is used by default, press Skip to keep it", intro, progress(1))
+	return menuReply(chatID, strings.TrimSpace(text), keyboard)
+}
+
+// locationStepReply asks for city or location with back and cancel buttons
+func locationStepReply(chatID int, intro string) (url.Values, error) {
+	jsonData, jsonErr := utilities.ButtonMarshal(utilities.OnboardingLocationButtons)
+	if jsonErr != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %w", jsonErr)
+	}
+
+	text := fmt.Sprintf("%v\n%v. Please enter city or share location to update the city for weather forecast", intro, progress(2))
+	return url.Values{
+		"chat_id":      {strconv.Itoa(chatID)},
+		"text":         {strings.TrimSpace(text)},
+		"reply_markup": {string(jsonData)},
+	}, nil
+}
+
+// progress returns indicator of onboarding step
+func progress(step int) string {
+	return fmt.Sprintf("Step %v of %v %v%v", step, onboardingSteps, strings.Repeat("●", step), strings.Repeat("○", onboardingSteps-step))
+}
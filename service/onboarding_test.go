@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStateMachine_Handle_onboarding(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	telegramService := mocks.NewTelegramService(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), telegramService, mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}, UserTime: "08:00"}
+
+	tests := []struct {
+		name       string
+		text       string
+		callback   string
+		state      db.SubscriptionStatus
+		want       string
+		setupMocks func()
+	}{
+		{
+			name:  "skip time step",
+			text:  "skip",
+			state: db.Subscribed,
+			want:  "Default time 08:00 UTC kept.\nStep 2 of 2 ●●. Please enter city or share location to update the city for weather forecast",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.TimeUpdated}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:  "cancel time step",
+			text:  "Cancel",
+			state: db.Subscribed,
+			want:  "Subscription cancelled. Press Subscribe to start again",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.NewUser}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:  "back to time step",
+			text:  "« Back",
+			state: db.TimeUpdated,
+			want:  "Step 1 of 2 ●○. Please pick hour below or enter time for weather forecast every day.Example: /time 15:00 or 7am.\nTime when subscribed is used by default, press Skip to keep it",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.Subscribed}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:  "cancel location step",
+			text:  "Cancel",
+			state: db.TimeUpdated,
+			want:  "Subscription cancelled. Press Subscribe to start again",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.NewUser}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:     "skip button",
+			callback: "onboarding:skip",
+			state:    db.Subscribed,
+			want:     "Default time 08:00 UTC kept",
+			setupMocks: func() {
+				gomock.InOrder(
+					storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.TimeUpdated}}}}, user.ID).Return(nil),
+					telegramService.EXPECT().SendResponse(358383178, gomock.Any()).Return(nil),
+				)
+			},
+		},
+		{
+			name:     "cancel button",
+			callback: "onboarding:cancel",
+			state:    db.Subscribed,
+			want:     "Subscription cancelled. Press Subscribe to start again",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.NewUser}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:       "skip button after time step",
+			callback:   "onboarding:skip",
+			state:      db.LocationProvided,
+			want:       "This step is already completed",
+			setupMocks: func() {},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			var (
+				got url.Values
+				err error
+			)
+			if tc.callback != "" {
+				got, err = subscriptionFlow.HandleCallback(tgService, tc.callback, user, tc.state, 358383178)
+			} else {
+				got, err = subscriptionFlow.Handle(tgService, requestBody(t, tc.text), user, tc.state, 358383178)
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+	t.Run("location is not requested if time step isn't stored", func(t *testing.T) {
+		storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.TimeUpdated}}}}, user.ID).Return(errors.New("connection refused"))
+
+		_, err := subscriptionFlow.HandleCallback(tgService, "onboarding:skip", user, db.Subscribed, 358383178)
+		assert.Error(t, err)
+	})
+}
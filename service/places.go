@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"subscriptionbot/db"
+	weatherAPI "subscriptionbot/weather"
+	"time"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxPlaces user can save
+const maxPlaces = 5
+
+const placesUsage = "Example: /places add Office New York, /places remove Office, /places include Office, /places exclude Office, /places list"
+
+// placesCommand handles /places add|remove|include|exclude|list
+func (s *Service) placesCommand(args string, user db.User, chatID int) (url.Values, error) {
+	action, rest, _ := strings.Cut(args, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch strings.ToLower(action) {
+	case "", "list":
+		return placesReply(chatID, formatPlaces(user.Places)), nil
+	case "add":
+		return s.placeAdd(rest, user, chatID)
+	case "remove":
+		return s.placeRemove(rest, user, chatID)
+	case "include":
+		return s.placeInclude(rest, true, user, chatID)
+	case "exclude":
+		return s.placeInclude(rest, false, user, chatID)
+	default:
+		return placesReply(chatID, fmt.Sprintf("unknown action %v. %v", action, placesUsage)), nil
+	}
+}
+
+// placeAdd saves a place with given city. Without city current location of subscription is saved
+func (s *Service) placeAdd(args string, user db.User, chatID int) (url.Values, error) {
+	name, city, _ := strings.Cut(args, " ")
+	city = strings.TrimSpace(city)
+	if name == "" {
+		return placesReply(chatID, fmt.Sprintf("place name is missing. %v", placesUsage)), nil
+	}
+	if _, found := findPlace(user.Places, name); found {
+		return placesReply(chatID, fmt.Sprintf("place %v already exists", name)), nil
+	}
+	if len(user.Places) >= maxPlaces {
+		return placesReply(chatID, fmt.Sprintf("you can save up to %v places. Remove one to add another", maxPlaces)), nil
+	}
+
+	place := db.Place{Name: name, City: city, Included: true}
+	if city == "" {
+		place.City = user.City
+		place.Location = user.Location
+	}
+
+	//Checking if place can be used in weather request
+	if _, weatherErr := s.Weather.CurrentWeather(context.Background(), placeUser(user, place)); weatherErr != nil {
+		return placesReply(chatID, fmt.Sprintf("unable to find weather for %v. Enter a city or share location first", name)), nil
+	}
+
+	user.Places = append(user.Places, place)
+	if updateErr := s.updatePlaces(user); updateErr != nil {
+		return nil, updateErr
+	}
+
+	return placesReply(chatID, fmt.Sprintf("Place %v saved and included in forecast", name)), nil
+}
+
+func (s *Service) placeRemove(name string, user db.User, chatID int) (url.Values, error) {
+	index, found := findPlace(user.Places, name)
+	if !found {
+		return placesReply(chatID, fmt.Sprintf("place %v not found", name)), nil
+	}
+
+	user.Places = append(user.Places[:index], user.Places[index+1:]...)
+	if updateErr := s.updatePlaces(user); updateErr != nil {
+		return nil, updateErr
+	}
+
+	return placesReply(chatID, fmt.Sprintf("Place %v removed", name)), nil
+}
+
+func (s *Service) placeInclude(name string, included bool, user db.User, chatID int) (url.Values, error) {
+	index, found := findPlace(user.Places, name)
+	if !found {
+		return placesReply(chatID, fmt.Sprintf("place %v not found", name)), nil
+	}
+
+	user.Places[index].Included = included
+	if updateErr := s.updatePlaces(user); updateErr != nil {
+		return nil, updateErr
+	}
+
+	if included {
+		return placesReply(chatID, fmt.Sprintf("Place %v included in forecast", user.Places[index].Name)), nil
+	}
+	return placesReply(chatID, fmt.Sprintf("Place %v excluded from forecast", user.Places[index].Name)), nil
+}
+
+func (s *Service) updatePlaces(user db.User) error {
+	places := user.Places
+	if places == nil {
+		places = []db.Place{}
+	}
+
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"places", places},
+	}}}, user.ID)
+}
+
+// placesForecast renders forecast with day-over-day temperature change for every place included in scheduled forecast.
+// Returns today's observations of the places
+func (s *Service) placesForecast(ctx context.Context, user db.User, sentAt time.Time) (string, []db.Observation) {
+	var (
+		sections     []string
+		observations []db.Observation
+	)
+	for _, place := range user.Places {
+		if !place.Included {
+			continue
+		}
+		weather, weatherErr := s.Weather.CurrentWeather(ctx, placeUser(user, place))
+		if weatherErr != nil {
+			log.Error().Err(weatherErr).Msgf("unable to get weather for place %v of ChatID:%v", place.Name, user.ChatID)
+			continue
+		}
+		section := fmt.Sprintf("📍%v\n%v", place.Name, weatherAPI.FormatForecast(weather))
+		if !weather.Stale {
+			observation := newObservation(placeUser(user, place), weather, sentAt)
+			if delta, hasDelta := temperatureDelta(findObservation(user.Observations, observation.Place), observation); hasDelta {
+				section = fmt.Sprintf("%v\n%v", section, deltaText(delta))
+			}
+			observations = append(observations, observation)
+		}
+		sections = append(sections, section)
+	}
+
+	return strings.Join(sections, "\n\n"), observations
+}
+
+// placeUser returns user with city and location of the place for weather requests
+func placeUser(user db.User, place db.Place) db.User {
+	user.City = place.City
+	user.Location = place.Location
+	return user
+}
+
+func findPlace(places []db.Place, name string) (int, bool) {
+	for i, place := range places {
+		if strings.EqualFold(place.Name, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func formatPlaces(places []db.Place) string {
+	if len(places) == 0 {
+		return fmt.Sprintf("You have no saved places. %v", placesUsage)
+	}
+
+	lines := []string{"Your places:"}
+	for _, place := range places {
+		where := place.City
+		if where == "" {
+			where = fmt.Sprintf("%.4f, %.4f", place.Location.Latitude, place.Location.Longitude)
+		}
+		mark := "✅"
+		if !place.Included {
+			mark = "➖"
+		}
+		lines = append(lines, fmt.Sprintf("%v %v: %v", mark, place.Name, where))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func placesReply(chatID int, text string) url.Values {
+	return url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"text":    {text},
+	}
+}
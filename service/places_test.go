@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestService_placesCommand(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+
+	user := db.User{
+		ID:     primitive.ObjectID{1},
+		City:   "Kyiv",
+		ChatID: 358383178,
+		Places: []db.Place{{Name: "Parents", City: "Lviv", Included: true}},
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		want       string
+		setupMocks func()
+	}{
+		{
+			name: "add place with city",
+			args: "add Office New York",
+			want: "Place Office saved and included in forecast",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), placeUser(user, db.Place{City: "New York"})).Return(weatherAPI.WeatherData{}, nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"places", []db.Place{
+					{Name: "Parents", City: "Lviv", Included: true},
+					{Name: "Office", City: "New York", Included: true},
+				}}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name: "add place with current location",
+			args: "add Home",
+			want: "Place Home saved and included in forecast",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), user).Return(weatherAPI.WeatherData{}, nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"places", []db.Place{
+					{Name: "Parents", City: "Lviv", Included: true},
+					{Name: "Home", City: "Kyiv", Included: true},
+				}}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:       "add existing place",
+			args:       "add parents Odesa",
+			want:       "place parents already exists",
+			setupMocks: func() {},
+		},
+		{
+			name: "exclude place",
+			args: "exclude parents",
+			want: "Place Parents excluded from forecast",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"places", []db.Place{
+					{Name: "Parents", City: "Lviv", Included: false},
+				}}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:       "remove unknown place",
+			args:       "remove Office",
+			want:       "place Office not found",
+			setupMocks: func() {},
+		},
+		{
+			name:       "list places",
+			args:       "list",
+			want:       "Your places:\n✅ Parents: Lviv",
+			setupMocks: func() {},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			subscriber := user
+			subscriber.Places = append([]db.Place{}, user.Places...)
+
+			got, err := tgService.placesCommand(tc.args, subscriber, user.ChatID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
+
+func TestService_placesForecast(t *testing.T) {
+	controller := gomock.NewController(t)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(mocks.NewMongoStorage(controller), weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	sentAt := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	parents := db.Place{Name: "Parents", City: "Lviv", Included: true}
+	user := db.User{
+		City:         "Kyiv",
+		Places:       []db.Place{parents, {Name: "Office", City: "Kyiv"}},
+		Observations: []db.Observation{{Date: "2024-03-09", Place: "Lviv", Temp: 9.4}},
+	}
+	lviv := weatherAPI.WeatherData{Name: "Lviv", Main: weatherAPI.Main{Temp: 4.6}}
+
+	tests := []struct {
+		name             string
+		weather          weatherAPI.WeatherData
+		want             string
+		wantObservations []db.Observation
+	}{
+		{
+			name:             "place with yesterday's observation",
+			weather:          lviv,
+			want:             "📍Parents\n" + weatherAPI.FormatForecast(lviv) + "\n5° colder than yesterday",
+			wantObservations: []db.Observation{{Date: "2024-03-10", Place: "Lviv", Temp: 4.6}},
+		},
+		{
+			name:    "stale weather",
+			weather: weatherAPI.WeatherData{Name: "Lviv", Main: weatherAPI.Main{Temp: 4.6}, Stale: true},
+			want:    "📍Parents\n" + weatherAPI.FormatForecast(weatherAPI.WeatherData{Name: "Lviv", Main: weatherAPI.Main{Temp: 4.6}, Stale: true}),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			weather.EXPECT().CurrentWeather(gomock.Any(), placeUser(user, parents)).Return(tc.weather, nil)
+
+			got, observations := tgService.placesForecast(context.Background(), user, sentAt)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantObservations, observations)
+		})
+	}
+}
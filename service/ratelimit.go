@@ -0,0 +1,79 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitConfig struct for on-demand forecasts allowed per user
+type RateLimitConfig struct {
+	Limit  int           `env:"NOW_RATE_LIMIT" envDefault:"3"`
+	Window time.Duration `env:"NOW_RATE_WINDOW" envDefault:"10m"`
+}
+
+// RateLimiter allows a limited number of requests per user in a sliding window
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	requests map[int][]time.Time
+	prunedAt time.Time
+}
+
+// NewRateLimiter creates rate limiter. Limit of 0 means requests are not limited
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		limit:    cfg.Limit,
+		window:   cfg.Window,
+		requests: make(map[int][]time.Time),
+	}
+}
+
+// Allow counts request of a user if it is within the limit. Otherwise returns time left until next request is allowed
+func (l *RateLimiter) Allow(userID int, now time.Time) (bool, time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	//Requests outside the window are dropped
+	recent := l.requests[userID][:0]
+	for _, requested := range l.requests[userID] {
+		if now.Sub(requested) < l.window {
+			recent = append(recent, requested)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.requests[userID] = recent
+		return false, recent[0].Add(l.window).Sub(now)
+	}
+
+	l.requests[userID] = append(recent, now)
+	return true, 0
+}
+
+// prune removes users without requests in the window once per window, so users who stopped sending requests are not kept
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.prunedAt) < l.window {
+		return
+	}
+	l.prunedAt = now
+
+	for userID, requests := range l.requests {
+		if len(requests) == 0 || now.Sub(requests[len(requests)-1]) >= l.window {
+			delete(l.requests, userID)
+		}
+	}
+}
+
+// size returns number of users with tracked requests
+func (l *RateLimiter) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.requests)
+}
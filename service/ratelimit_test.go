@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{Limit: 2, Window: 10 * time.Minute})
+	now := time.Date(2023, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		userID   int
+		at       time.Time
+		want     bool
+		wantWait time.Duration
+	}{
+		{name: "first request", userID: 1, at: now, want: true},
+		{name: "second request", userID: 1, at: now.Add(time.Minute), want: true},
+		{name: "limit reached", userID: 1, at: now.Add(2 * time.Minute), want: false, wantWait: 8 * time.Minute},
+		{name: "other user", userID: 2, at: now.Add(2 * time.Minute), want: true},
+		{name: "first request left the window", userID: 1, at: now.Add(10 * time.Minute), want: true},
+		{name: "limit reached again", userID: 1, at: now.Add(10 * time.Minute), want: false, wantWait: time.Minute},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, wait := limiter.Allow(tc.userID, tc.at)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantWait, wait)
+		})
+	}
+}
+
+func TestRateLimiter_prune(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{Limit: 2, Window: 10 * time.Minute})
+	now := time.Date(2023, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	limiter.Allow(1, now)
+	limiter.Allow(2, now.Add(5*time.Minute))
+	assert.Equal(t, 2, limiter.size())
+
+	limiter.Allow(3, now.Add(12*time.Minute))
+	assert.Equal(t, 2, limiter.size(), "user without requests in the window is removed")
+
+	limiter.Allow(3, now.Add(30*time.Minute))
+	assert.Equal(t, 1, limiter.size())
+}
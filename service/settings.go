@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/utilities"
+)
+
+// settings sections that open a submenu
+const (
+	settingsLocation = "location"
+	settingsUnits    = "units"
+	settingsFormat   = "format"
+	settingsAlerts   = "alerts"
+	settingsTimeZone = "timezone"
+	settingsLanguage = "language"
+)
+
+// Time zone and language can't be changed yet, their buttons explain it
+const (
+	timeZoneText = "Forecast time is in UTC. Other time zones are not supported yet, set delivery time in UTC"
+	languageText = "Forecast is in English. Other languages are not supported yet"
+)
+
+// alert options user can pick in settings
+var (
+	swingOptions  = []string{utilities.Off, "3", "5", "10"}
+	goldenOptions = []string{utilities.Off, "15", "30", "60"}
+)
+
+// settingsCommand handles /settings
+func (s *Service) settingsCommand(_ string, user db.User, chatID int) (url.Values, error) {
+	return menuReply(chatID, formatSettings(user), settingsKeyboard())
+}
+
+// settingsCallback shows settings overview or a submenu to edit one of the settings
+func (s *Service) settingsCallback(section string, user db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	var (
+		reply    url.Values
+		replyErr error
+	)
+	switch section {
+	case settingsLocation:
+		reply, replyErr = menuReply(chatID, locationText, backToSettings(utilities.ChoiceMenu(utilities.LocationCallback, liveOptions, liveOption(user))))
+	case settingsUnits:
+		reply, replyErr = menuReply(chatID, "Choose units for weather forecast", backToSettings(utilities.ChoiceMenu(utilities.UnitsCallback, unitsOptions, user.Units)))
+	case settingsFormat:
+		reply, replyErr = menuReply(chatID, "Choose forecast format", backToSettings(utilities.ChoiceMenu(utilities.FormatCallback, formatOptions, user.Format)))
+	case settingsAlerts:
+		reply, replyErr = alertsMenu(chatID, alertsText, user)
+	case settingsTimeZone:
+		reply, replyErr = menuReply(chatID, timeZoneText, backToSettings(utilities.InlineKeyboardMarkup{}))
+	case settingsLanguage:
+		reply, replyErr = menuReply(chatID, languageText, backToSettings(utilities.InlineKeyboardMarkup{}))
+	default:
+		reply, replyErr = menuReply(chatID, formatSettings(user), settingsKeyboard())
+	}
+
+	return Step{Reply: reply}, replyErr
+}
+
+// swingCallback sets temperature change alert from alerts menu
+func (s *Service) swingCallback(value string, user db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	result, updateErr := s.swingUpdate(value, user, chatID)
+	if updateErr != nil {
+		return Step{}, updateErr
+	}
+
+	if threshold, parseErr := thresholdValue(value); parseErr == nil {
+		user.SwingThreshold = threshold
+	}
+	reply, replyErr := alertsMenu(chatID, result.Get("text"), user)
+	return Step{Reply: reply}, replyErr
+}
+
+// goldenHourCallback sets golden hour reminder from alerts menu
+func (s *Service) goldenHourCallback(value string, user db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	result, updateErr := s.goldenHourUpdate(value, user, chatID)
+	if updateErr != nil && result.Get("text") == "" {
+		return Step{}, updateErr
+	}
+
+	if reminder, parseErr := thresholdValue(value); parseErr == nil && updateErr == nil {
+		user.GoldenHourReminder = reminder
+	}
+	reply, replyErr := alertsMenu(chatID, result.Get("text"), user)
+	return Step{Reply: reply}, replyErr
+}
+
+const alertsText = "Choose temperature change in degrees to be alerted about, first row, and minutes to be reminded before golden hour, second row"
+
+// alertsMenu shows alert options with current ones marked
+func alertsMenu(chatID int, text string, user db.User) (url.Values, error) {
+	swing := utilities.ChoiceMenu(utilities.SwingCallback, swingOptions, alertOption(user.SwingThreshold))
+	golden := utilities.ChoiceMenu(utilities.GoldenCallback, goldenOptions, alertOption(user.GoldenHourReminder))
+	keyboard := utilities.InlineKeyboardMarkup{InlineKeyboard: append(swing.InlineKeyboard, golden.InlineKeyboard...)}
+
+	return menuReply(chatID, text, backToSettings(keyboard))
+}
+
+// settingsKeyboard has a button for every setting that can be edited
+func settingsKeyboard() utilities.InlineKeyboardMarkup {
+	return utilities.InlineKeyboardMarkup{InlineKeyboard: [][]utilities.InlineKeyboardButton{
+		{
+			{Text: "🕖 Time", CallbackData: utilities.CallbackData(utilities.TimeCallback, "")},
+			{Text: "📍 Location", CallbackData: utilities.CallbackData(utilities.SettingsCallback, settingsLocation)},
+		},
+		{
+			{Text: "📏 Units", CallbackData: utilities.CallbackData(utilities.SettingsCallback, settingsUnits)},
+			{Text: "🖼 Format", CallbackData: utilities.CallbackData(utilities.SettingsCallback, settingsFormat)},
+		},
+		{
+			{Text: "🌐 Time zone", CallbackData: utilities.CallbackData(utilities.SettingsCallback, settingsTimeZone)},
+			{Text: "🗣 Language", CallbackData: utilities.CallbackData(utilities.SettingsCallback, settingsLanguage)},
+		},
+		{
+			{Text: "🔔 Alerts", CallbackData: utilities.CallbackData(utilities.SettingsCallback, settingsAlerts)},
+		},
+	}}
+}
+
+// formatSettings renders overview of user's settings
+func formatSettings(user db.User) string {
+	location := user.City
+	if location == "" && (user.Location.Latitude != 0 || user.Location.Longitude != 0) {
+		location = fmt.Sprintf("%.4f, %.4f", user.Location.Latitude, user.Location.Longitude)
+		if user.Place != "" {
+			location = fmt.Sprintf("%v (%v)", user.Place, location)
+		}
+	}
+	if location != "" && user.LocationLocked {
+		location += " 🔒"
+	}
+
+	lines := []string{
+		"⚙️Your settings",
+		fmt.Sprintf("🕖Delivery time: %v", orDefault(user.UserTime, "not set")),
+		"🌐Time zone: UTC",
+		fmt.Sprintf("📍Location: %v", orDefault(location, "not set")),
+		fmt.Sprintf("📏Units: %v", orDefault(user.Units, "default")),
+		"🗣Language: English",
+		fmt.Sprintf("🖼Format: %v", orDefault(user.Format, utilities.FormatText)),
+		fmt.Sprintf("🔔Alerts: %v", formatAlerts(user)),
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatAlerts(user db.User) string {
+	var alerts []string
+	if user.SwingThreshold > 0 {
+		alerts = append(alerts, fmt.Sprintf("temperature change of %v°", user.SwingThreshold))
+	}
+	if user.GoldenHourReminder > 0 {
+		alerts = append(alerts, fmt.Sprintf("golden hour %v minutes before", user.GoldenHourReminder))
+	}
+	if len(alerts) == 0 {
+		return utilities.Off
+	}
+	return strings.Join(alerts, ", ")
+}
+
+func backToSettings(keyboard utilities.InlineKeyboardMarkup) utilities.InlineKeyboardMarkup {
+	return utilities.WithBackButton(keyboard, "« Settings", utilities.CallbackData(utilities.SettingsCallback, ""))
+}
+
+// alertOption returns menu option of alert value
+func alertOption(value int) string {
+	if value <= 0 {
+		return utilities.Off
+	}
+	return fmt.Sprint(value)
+}
+
+// thresholdValue parses alert option, off is 0
+func thresholdValue(value string) (int, error) {
+	if value == utilities.Off {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
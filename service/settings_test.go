@@ -0,0 +1,114 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestService_settingsCommand(t *testing.T) {
+	controller := gomock.NewController(t)
+	tgService := NewService(mocks.NewMongoStorage(controller), mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+
+	tests := []struct {
+		name string
+		user db.User
+		want string
+	}{
+		{
+			name: "defaults",
+			user: db.User{UserTime: "08:00"},
+			want: "⚙️Your settings\n🕖Delivery time: 08:00\n🌐Time zone: UTC\n📍Location: not set\n📏Units: default\n🗣Language: English\n🖼Format: text\n🔔Alerts: off",
+		},
+		{
+			name: "configured",
+			user: db.User{UserTime: "07:30", City: "Kyiv", Units: "imperial", Format: "chart", SwingThreshold: 5, GoldenHourReminder: 30},
+			want: "⚙️Your settings\n🕖Delivery time: 07:30\n🌐Time zone: UTC\n📍Location: Kyiv\n📏Units: imperial\n🗣Language: English\n🖼Format: chart\n🔔Alerts: temperature change of 5°, golden hour 30 minutes before",
+		},
+		{
+			name: "shared location",
+			user: db.User{UserTime: "07:30", Location: db.Location{Latitude: 50.45, Longitude: 30.5241}},
+			want: "⚙️Your settings\n🕖Delivery time: 07:30\n🌐Time zone: UTC\n📍Location: 50.4500, 30.5241\n📏Units: default\n🗣Language: English\n🖼Format: text\n🔔Alerts: off",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tgService.settingsCommand("", tc.user, 358383178)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+			assert.Contains(t, got.Get("reply_markup"), `"callback_data":"settings:alerts"`)
+			assert.Contains(t, got.Get("reply_markup"), `"callback_data":"settings:timezone"`)
+			assert.Contains(t, got.Get("reply_markup"), `"callback_data":"settings:language"`)
+		})
+	}
+}
+
+func TestStateMachine_HandleCallback_settings(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}, UserTime: "08:00", SwingThreshold: 3}
+
+	tests := []struct {
+		name       string
+		data       string
+		want       string
+		wantMarkup string
+		setupMocks func()
+	}{
+		{
+			name:       "alerts menu",
+			data:       "settings:alerts",
+			want:       alertsText,
+			wantMarkup: `{"inline_keyboard":[[{"text":"off","callback_data":"swing:off"},{"text":"✅ 3","callback_data":"swing:3"},{"text":"5","callback_data":"swing:5"},{"text":"10","callback_data":"swing:10"}],[{"text":"✅ off","callback_data":"goldenhour:off"},{"text":"15","callback_data":"goldenhour:15"},{"text":"30","callback_data":"goldenhour:30"},{"text":"60","callback_data":"goldenhour:60"}],[{"text":"« Settings","callback_data":"settings:"}]]}`,
+			setupMocks: func() {},
+		},
+		{
+			name:       "temperature alert changed",
+			data:       "swing:5",
+			want:       "You will be alerted when temperature changes by 5° or more since yesterday",
+			wantMarkup: `{"inline_keyboard":[[{"text":"off","callback_data":"swing:off"},{"text":"3","callback_data":"swing:3"},{"text":"✅ 5","callback_data":"swing:5"},{"text":"10","callback_data":"swing:10"}],[{"text":"✅ off","callback_data":"goldenhour:off"},{"text":"15","callback_data":"goldenhour:15"},{"text":"30","callback_data":"goldenhour:30"},{"text":"60","callback_data":"goldenhour:60"}],[{"text":"« Settings","callback_data":"settings:"}]]}`,
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"swingThreshold", 5}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:       "time zone",
+			data:       "settings:timezone",
+			want:       timeZoneText,
+			wantMarkup: `{"inline_keyboard":[[{"text":"« Settings","callback_data":"settings:"}]]}`,
+			setupMocks: func() {},
+		},
+		{
+			name:       "language",
+			data:       "settings:language",
+			want:       languageText,
+			wantMarkup: `{"inline_keyboard":[[{"text":"« Settings","callback_data":"settings:"}]]}`,
+			setupMocks: func() {},
+		},
+		{
+			name:       "back to overview",
+			data:       "settings:",
+			want:       "⚙️Your settings\n🕖Delivery time: 08:00\n🌐Time zone: UTC\n📍Location: not set\n📏Units: default\n🗣Language: English\n🖼Format: text\n🔔Alerts: temperature change of 3°",
+			setupMocks: func() {},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			got, err := subscriptionFlow.HandleCallback(tgService, tc.data, user, db.LocationProvided, 358383178)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+			if tc.wantMarkup != "" {
+				assert.JSONEq(t, tc.wantMarkup, got.Get("reply_markup"))
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Errors returned by state machine
+var (
+	ErrUnknownState      = errors.New("unknown subscription state")
+	ErrInvalidTransition = errors.New("invalid subscription state transition")
+	ErrUnknownCallback   = errors.New("unknown inline button action")
+	ErrNotConfigurable   = errors.New("settings are available after subscription")
+)
+
+// Step is a result of handling user input: reply, next state and fields to update together with the state.
+// Follow is sent as a new message once the state is stored, like a request of the next onboarding step
+type Step struct {
+	Reply  url.Values
+	Next   db.SubscriptionStatus
+	Set    bson.D
+	Follow url.Values
+}
+
+// stateHandler handles user input in a single state of the subscription flow
+type stateHandler func(s *Service, body *telegram.Update, user db.User, chatID int) (Step, error)
+
+// commandHandler handles a global command. Settings commands are available once subscription is finished. Args is the text after the command
+type commandHandler func(s *Service, args string, user db.User, chatID int) (url.Values, error)
+
+// stepCommand handles a command available in any state that can also move subscription flow forward.
+// Inline button callbacks are handled the same way with value of the button as args
+type stepCommand func(s *Service, args string, user db.User, state db.SubscriptionStatus, chatID int) (Step, error)
+
+// StateMachine describes subscription flow with named states, allowed transitions, per-state input handlers and global commands
+type StateMachine struct {
+	handlers    map[db.SubscriptionStatus]stateHandler
+	transitions map[db.SubscriptionStatus][]db.SubscriptionStatus
+	commands    map[string]commandHandler
+	steps       map[string]stepCommand
+	callbacks   map[string]stepCommand
+	limited     map[string]bool
+	settings    map[string]bool
+}
+
+// stateNames for logs and errors
+var stateNames = map[db.SubscriptionStatus]string{
+	db.NewUser:          "NewUser",
+	db.Subscribed:       "Subscribed",
+	db.TimeUpdated:      "TimeUpdated",
+	db.LocationProvided: "LocationProvided",
+}
+
+// subscriptionFlow is the onboarding flow NewUser → Subscribed → TimeUpdated → LocationProvided.
+// Onboarding steps can go back to the previous step or be cancelled back to NewUser
+var subscriptionFlow = &StateMachine{
+	handlers: map[db.SubscriptionStatus]stateHandler{
+		db.NewUser:          (*Service).userSubscribe,
+		db.Subscribed:       (*Service).userTimeRequest,
+		db.TimeUpdated:      (*Service).userLocationRequest,
+		db.LocationProvided: (*Service).answerHandle,
+	},
+	transitions: map[db.SubscriptionStatus][]db.SubscriptionStatus{
+		db.NewUser:     {db.Subscribed},
+		db.Subscribed:  {db.TimeUpdated, db.NewUser},
+		db.TimeUpdated: {db.LocationProvided, db.Subscribed, db.NewUser},
+	},
+	commands: map[string]commandHandler{
+		utilities.Unsubscribe:       (*Service).userUnsubscribe,
+		utilities.StopCommand:       (*Service).userUnsubscribe,
+		utilities.HelpCommand:       (*Service).helpCommand,
+		utilities.NowCommand:        (*Service).nowCommand,
+		utilities.SwingCommand:      (*Service).swingUpdate,
+		utilities.GoldenHourCommand: (*Service).goldenHourUpdate,
+		utilities.FormatCommand:     (*Service).formatUpdate,
+		utilities.PlacesCommand:     (*Service).placesCommand,
+		utilities.UnitsCommand:      (*Service).unitsCommand,
+		utilities.SettingsCommand:   (*Service).settingsCommand,
+		utilities.LocationCommand:   (*Service).locationCommand,
+		utilities.TripCommand:       (*Service).tripCommand,
+		utilities.CommuteCommand:    (*Service).commuteCommand,
+	},
+	limited: map[string]bool{
+		utilities.NowCommand: true,
+	},
+	//Commands and buttons changing forecast settings are available once subscription is finished
+	settings: map[string]bool{
+		utilities.SwingCommand:      true,
+		utilities.GoldenHourCommand: true,
+		utilities.FormatCommand:     true,
+		utilities.PlacesCommand:     true,
+		utilities.UnitsCommand:      true,
+		utilities.SettingsCommand:   true,
+		utilities.LocationCommand:   true,
+		utilities.TripCommand:       true,
+		utilities.CommuteCommand:    true,
+		utilities.UnitsCallback:     true,
+		utilities.FormatCallback:    true,
+		utilities.SettingsCallback:  true,
+		utilities.SwingCallback:     true,
+		utilities.GoldenCallback:    true,
+		utilities.LocationCallback:  true,
+	},
+	steps: map[string]stepCommand{
+		utilities.TimeCommand: (*Service).timeCommand,
+		utilities.CityCommand: (*Service).cityCommand,
+	},
+	callbacks: map[string]stepCommand{
+		utilities.UnitsCallback:      (*Service).unitsCallback,
+		utilities.FormatCallback:     (*Service).formatCallback,
+		utilities.TimeCallback:       (*Service).timePickerCallback,
+		utilities.HourCallback:       (*Service).hourCallback,
+		utilities.MinuteCallback:     (*Service).minuteCallback,
+		utilities.SettingsCallback:   (*Service).settingsCallback,
+		utilities.SwingCallback:      (*Service).swingCallback,
+		utilities.GoldenCallback:     (*Service).goldenHourCallback,
+		utilities.UndoCallback:       (*Service).undoCallback,
+		utilities.OnboardingCallback: (*Service).onboardingCallback,
+		utilities.LocationCallback:   (*Service).locationCallback,
+		utilities.CityCallback:       (*Service).cityCallback,
+	},
+}
+
+// StateName returns name of subscription state
+func StateName(state db.SubscriptionStatus) string {
+	if name, found := stateNames[state]; found {
+		return name
+	}
+	return fmt.Sprintf("State(%d)", state)
+}
+
+// CanTransition reports if flow can move from one state to another. Staying in the same state is always allowed
+func (m *StateMachine) CanTransition(from, to db.SubscriptionStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range m.transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle runs global command or state handler for user input and stores next state
+func (m *StateMachine) Handle(s *Service, body *telegram.Update, user db.User, state db.SubscriptionStatus, chatID int) (url.Values, error) {
+	if command, args, found := m.command(body.Message.Text); found {
+		name, _ := telegram.CommandName(body.Message.Text)
+		if !m.configurable(name, state) {
+			return subscribeFirst(chatID).Reply, nil
+		}
+		if m.limited[name] {
+			if reply, limited := s.rateLimited(body.Message, chatID); limited {
+				return reply, nil
+			}
+		}
+		return command(s, args, user, chatID)
+	}
+
+	step, stepErr := m.step(s, body, user, state, chatID)
+	if stepErr != nil {
+		return step.Reply, stepErr
+	}
+
+	return m.apply(s, user, state, step, chatID)
+}
+
+// configurable reports if command or button can be used in the state. Settings need finished subscription
+func (m *StateMachine) configurable(action string, state db.SubscriptionStatus) bool {
+	return !m.settings[action] || state == db.LocationProvided
+}
+
+// HandleCallback runs handler of pressed inline button and stores next state
+func (m *StateMachine) HandleCallback(s *Service, data string, user db.User, state db.SubscriptionStatus, chatID int) (url.Values, error) {
+	action, value := utilities.ParseCallbackData(data)
+	callback, found := m.callbacks[action]
+	if !found {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCallback, action)
+	}
+	if !m.configurable(action, state) {
+		return nil, fmt.Errorf("%w: %v", ErrNotConfigurable, action)
+	}
+
+	step, stepErr := callback(s, value, user, state, chatID)
+	if stepErr != nil {
+		return step.Reply, stepErr
+	}
+
+	return m.apply(s, user, state, step, chatID)
+}
+
+// apply checks transition of the step, stores next state with fields of the step and sends follow-up message
+func (m *StateMachine) apply(s *Service, user db.User, state db.SubscriptionStatus, step Step, chatID int) (url.Values, error) {
+	if step.Next == 0 {
+		step.Next = state
+	}
+	if !m.CanTransition(state, step.Next) {
+		return nil, fmt.Errorf("%w: %v → %v", ErrInvalidTransition, StateName(state), StateName(step.Next))
+	}
+
+	set := step.Set
+	if step.Next != state {
+		set = append(bson.D{{"subscriptionStatus", step.Next}}, set...)
+	}
+	if len(set) > 0 {
+		if updateErr := s.DB.Update(bson.D{{"$set", set}}, user.ID); updateErr != nil {
+			return nil, updateErr
+		}
+	}
+	if step.Follow != nil {
+		if sendErr := s.API.SendResponse(chatID, step.Follow); sendErr != nil {
+			log.Error().Err(sendErr).Msgf("unable to send next step to ChatID:%v", chatID)
+		}
+	}
+
+	return step.Reply, nil
+}
+
+// step runs command that can move the flow or handler of the current state
+func (m *StateMachine) step(s *Service, body *telegram.Update, user db.User, state db.SubscriptionStatus, chatID int) (Step, error) {
+	name, args := telegram.CommandName(body.Message.Text)
+	if command, found := m.steps[name]; found {
+		return command(s, args, user, state, chatID)
+	}
+
+	handler, found := m.handlers[state]
+	if !found {
+		return Step{}, fmt.Errorf("%w: %v", ErrUnknownState, StateName(state))
+	}
+	return handler(s, body, user, chatID)
+}
+
+// command returns global command handler for the first word of the text
+func (m *StateMachine) command(text string) (commandHandler, string, bool) {
+	name, args := telegram.CommandName(text)
+	command, found := m.commands[name]
+
+	return command, args, found
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"subscriptionbot/telegram"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStateMachine_CanTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		from db.SubscriptionStatus
+		to   db.SubscriptionStatus
+		want bool
+	}{
+		{name: "subscribe", from: db.NewUser, to: db.Subscribed, want: true},
+		{name: "time provided", from: db.Subscribed, to: db.TimeUpdated, want: true},
+		{name: "location provided", from: db.TimeUpdated, to: db.LocationProvided, want: true},
+		{name: "stay in state", from: db.LocationProvided, to: db.LocationProvided, want: true},
+		{name: "skip time step", from: db.Subscribed, to: db.LocationProvided, want: false},
+		{name: "new user with location", from: db.NewUser, to: db.LocationProvided, want: false},
+		{name: "back to new user", from: db.LocationProvided, to: db.NewUser, want: false},
+		{name: "back to time step", from: db.TimeUpdated, to: db.Subscribed, want: true},
+		{name: "cancel time step", from: db.Subscribed, to: db.NewUser, want: true},
+		{name: "cancel location step", from: db.TimeUpdated, to: db.NewUser, want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, subscriptionFlow.CanTransition(tc.from, tc.to))
+		})
+	}
+}
+
+func TestStateMachine_Handle(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}}
+
+	t.Run("unsubscribe while time is requested", func(t *testing.T) {
+		storage.EXPECT().Update(deactivation{}, user.ID).Return(nil)
+
+		got, err := subscriptionFlow.Handle(tgService, requestBody(t, "Unsubscribe"), user, db.Subscribed, 358383178)
+		require.NoError(t, err)
+		assert.Equal(t, "You have unsubscribed from weather forecast", got.Get("text"))
+	})
+
+	t.Run("command with arguments", func(t *testing.T) {
+		storage.EXPECT().Update(bson.D{{"$set", bson.D{{"swingThreshold", 5}}}}, user.ID).Return(nil)
+
+		_, err := subscriptionFlow.Handle(tgService, requestBody(t, "/swing 5"), user, db.LocationProvided, 358383178)
+		require.NoError(t, err)
+	})
+
+	t.Run("settings command before subscription is finished", func(t *testing.T) {
+		for _, state := range []db.SubscriptionStatus{db.NewUser, db.Subscribed, db.TimeUpdated} {
+			got, err := subscriptionFlow.Handle(tgService, requestBody(t, "/swing 5"), user, state, 358383178)
+			require.NoError(t, err)
+			assert.Equal(t, "Please subscribe to continue", got.Get("text"), StateName(state))
+		}
+	})
+
+	t.Run("state is kept on invalid input", func(t *testing.T) {
+		got, err := subscriptionFlow.Handle(tgService, requestBody(t, "Hello"), user, db.NewUser, 358383178)
+		require.NoError(t, err)
+		assert.Equal(t, "Please subscribe to continue", got.Get("text"))
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		_, err := subscriptionFlow.Handle(tgService, requestBody(t, "Hello"), user, db.SubscriptionStatus(42), 358383178)
+		assert.ErrorIs(t, err, ErrUnknownState)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		machine := &StateMachine{
+			handlers: map[db.SubscriptionStatus]stateHandler{
+				db.NewUser: func(s *Service, body *telegram.Update, user db.User, chatID int) (Step, error) {
+					return Step{Reply: url.Values{"chat_id": {strconv.Itoa(chatID)}}, Next: db.LocationProvided}, nil
+				},
+			},
+			transitions: subscriptionFlow.transitions,
+		}
+
+		_, err := machine.Handle(tgService, requestBody(t, "Subscribe"), user, db.NewUser, 358383178)
+		assert.ErrorIs(t, err, ErrInvalidTransition)
+	})
+}
+
+// deactivation matches update that marks user inactive since now
+type deactivation struct{}
+
+func (deactivation) Matches(x interface{}) bool {
+	update, ok := x.(bson.D)
+	if !ok || len(update) != 1 || update[0].Key != "$set" {
+		return false
+	}
+	set, ok := update[0].Value.(bson.D)
+	if !ok || len(set) != 2 || set[0] != (bson.E{Key: "inactive", Value: true}) || set[1].Key != "unsubscribedAt" {
+		return false
+	}
+	at, ok := set[1].Value.(time.Time)
+	return ok && time.Since(at) < time.Minute
+}
+
+func (deactivation) String() string {
+	return "marks user inactive"
+}
@@ -6,37 +6,57 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"subscriptionbot/db"
+	"subscriptionbot/telegram"
 	"subscriptionbot/utilities"
 	weatherAPI "subscriptionbot/weather"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	api "github.com/c1kzy/Telegram-API"
-
+	"github.com/phuslu/log"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type SubscriptionService interface {
-	AddSubscription(body *api.WebHookReqBody, chatID int) (url.Values, error)
+	AddSubscription(body *telegram.Update, chatID int) (url.Values, error)
 	Notify(ctx context.Context) error
 	TickUser(ctx context.Context) error
 }
 
 // Service struct for DB, weather, api
 type Service struct {
-	DB      db.Storage
-	Weather weatherAPI.WeatherService
-	API     api.TelegramService
+	DB        db.Storage
+	Weather   weatherAPI.WeatherService
+	API       api.TelegramService
+	Bot       telegram.BotService
+	Limiter   *RateLimiter
+	Admins    *AdminCache
+	Retention RetentionConfig
 }
 
-func NewService(DB db.Storage, weather weatherAPI.WeatherService, API api.TelegramService) *Service {
-	return &Service{DB: DB, Weather: weather, API: API}
+func NewService(DB db.Storage, weather weatherAPI.WeatherService, API api.TelegramService, bot telegram.BotService) *Service {
+	return &Service{
+		DB:      DB,
+		Weather: weather,
+		API:     API,
+		Bot:     bot,
+		Limiter: NewRateLimiter(RateLimitConfig{Limit: 3, Window: 10 * time.Minute}),
+		Admins:  NewAdminCache(AdminCacheConfig{TTL: 5 * time.Minute}),
+		Retention: RetentionConfig{
+			UndoPeriod:      15 * time.Minute,
+			Retention:       30 * 24 * time.Hour,
+			PurgeInterval:   time.Hour,
+			IdleTimeout:     24 * time.Hour,
+			AbandonedPeriod: 7 * 24 * time.Hour,
+		},
+	}
 }
 
 // AddSubscription function handles user subscriptions
-func (s *Service) AddSubscription(body *api.WebHookReqBody, chatID int) (url.Values, error) {
+func (s *Service) AddSubscription(body *telegram.Update, chatID int) (url.Values, error) {
 	currentTime := time.Now().UTC()
 	var (
 		user    db.User
@@ -47,9 +67,26 @@ func (s *Service) AddSubscription(body *api.WebHookReqBody, chatID int) (url.Val
 		return url.Values{}, fmt.Errorf("error marshaling JSON: %w", jsonErr)
 	}
 
-	user, userErr = s.DB.GetUser(body.Message.Chat.Username)
+	kind := ClassifyMessage(body.Message)
+	if kind == ServiceUpdate {
+		return s.serviceMessage(body.Message)
+	}
+
+	if body.Message.Chat.IsGroup() {
+		//Photos and stickers of group members are not addressed to bot
+		if !isSupported(kind) {
+			return nil, nil
+		}
+		allowed, reply, accessErr := s.groupAccess(body.Message)
+		if !allowed {
+			return reply, accessErr
+		}
+	}
+
+	user, userErr = s.DB.GetUser(body.Message.Chat.ID)
 	if errors.Is(userErr, db.ErrNotFound) {
 		newUser := db.User{
+			UserID:             body.Message.From.ID,
 			Username:           body.Message.Chat.Username,
 			SubscriptionStatus: int(db.NewUser),
 			UserTime:           currentTime.Round(1 * time.Second).Format("15:04"),
@@ -66,6 +103,17 @@ func (s *Service) AddSubscription(body *api.WebHookReqBody, chatID int) (url.Val
 		}, nil
 	}
 
+	if userErr != nil {
+		return nil, userErr
+	}
+	if touchErr := s.DB.Touch(user.ID, currentTime); touchErr != nil {
+		log.Error().Err(touchErr).Msgf("unable to store last interaction of ChatID:%v", chatID)
+	}
+
+	if user.Inactive {
+		return s.inactiveUser(body, user, chatID)
+	}
+
 	userSubscriptionStatus, statusErr := s.DB.UserSubscriptionStatus(user.ID)
 	if statusErr != nil {
 		return url.Values{
@@ -74,227 +122,254 @@ func (s *Service) AddSubscription(body *api.WebHookReqBody, chatID int) (url.Val
 		}, nil
 	}
 
-	switch userSubscriptionStatus {
-	case int(db.NewUser):
-		return s.userSubscribe(body, chatID, user.ID)
-	case int(db.Subscribed):
-		return s.userTimeRequest(body, chatID, user.ID)
-	case int(db.TimeUpdated):
-		return s.userLocationRequest(body, chatID, user)
-	default:
-		return s.answerHandle(body, chatID, user)
+	state := db.SubscriptionStatus(userSubscriptionStatus)
+	switch kind {
+	case LocationUpdate:
+		if state == db.LocationProvided && body.Message.Location.LivePeriod > 0 {
+			return s.liveStart(body.Message, user, chatID)
+		}
+	case VenueUpdate:
+		body.Message.Location = body.Message.Venue.Location
+	case ContactUpdate, MediaUpdate, UnknownUpdate:
+		return unsupportedReply(kind, state, chatID)
+	}
 
+	if s.stepExpired(user, state, currentTime) && !isStepAnswer(body.Message) {
+		return resumeOnboarding(state, chatID)
 	}
+
+	return subscriptionFlow.Handle(s, body, user, state, chatID)
 }
 
-func (s *Service) userTimeRequest(body *api.WebHookReqBody, chatID int, id primitive.ObjectID) (url.Values, error) {
-	jsonData, jsonErr := utilities.ButtonMarshal(utilities.LocationButton)
-	if jsonErr != nil {
-		return url.Values{}, fmt.Errorf("error marshaling JSON: %w", jsonErr)
+func (s *Service) userTimeRequest(body *telegram.Update, user db.User, chatID int) (Step, error) {
+	switch {
+	case strings.EqualFold(body.Message.Text, utilities.Skip):
+		reply, replyErr := locationStepReply(chatID, fmt.Sprintf("Default time %v UTC kept.", user.UserTime))
+		return Step{Reply: reply, Next: db.TimeUpdated}, replyErr
+	case strings.EqualFold(body.Message.Text, utilities.Cancel):
+		return cancelOnboarding(chatID)
 	}
-	//Unsubscribe option in case user decided to unsubscribe
-	if body.Message.Text == utilities.Unsubscribe {
-		if _, err := s.userUnsubscribe(id, chatID); err != nil {
-			return url.Values{}, err
-		}
 
-	}
 	userTime, timeErr := utilities.ConvertTime(body.Message.Text)
 	if timeErr != nil {
-		return url.Values{
+		return Step{Reply: url.Values{
 			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {"invalid time, try again.Example: 12:00"},
-		}, timeErr
+			"text":    {utilities.InvalidTime},
+		}}, timeErr
 	}
-	update := bson.D{{"$set", bson.D{
-		{"subscriptionStatus", db.TimeUpdated},
-		{"userTime", userTime},
-	}}}
 
-	updateErr := s.DB.Update(update, id)
-	if updateErr != nil {
-		return nil, updateErr
+	reply, replyErr := locationStepReply(chatID, fmt.Sprintf("User time updated to %v UTC.", userTime))
+	if replyErr != nil {
+		return Step{}, replyErr
 	}
-	return url.Values{
-		"chat_id":      {strconv.Itoa(chatID)},
-		"text":         {"User time updated. Please enter city or share location to update the city for weather forecast"},
-		"reply_markup": {string(jsonData)},
+	return Step{
+		Reply: reply,
+		Next:  db.TimeUpdated,
+		Set:   bson.D{{"userTime", userTime}},
 	}, nil
-
 }
 
-func (s *Service) userSubscribe(body *api.WebHookReqBody, chatID int, id primitive.ObjectID) (url.Values, error) {
+func (s *Service) userSubscribe(body *telegram.Update, user db.User, chatID int) (Step, error) {
 	if body.Message.Text == utilities.Subscribe {
 		currentTime := fmt.Sprintf("%02d:%02d", time.Now().Hour(), time.Now().Minute())
-		update := bson.D{{"$set", bson.D{
-			{"subscriptionStatus", db.Subscribed},
-			{"userTime", currentTime},
-		}}}
-		err := s.DB.Update(update, id)
-		if err != nil {
-			return nil, err
+		reply, replyErr := timeStepReply(chatID, "You have subscribed to weather forecast!")
+		if replyErr != nil {
+			return Step{}, replyErr
 		}
-		return url.Values{
-			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {"You have subscribed to weather forecast! Please enter time to provide time in 24H format for weather forecast every day.Example: /time 15:00.\nTime when subscribed is used by default"},
+		return Step{
+			Reply: reply,
+			Next:  db.Subscribed,
+			Set:   bson.D{{"userTime", currentTime}},
 		}, nil
 	}
 
-	return url.Values{
+	return Step{Reply: url.Values{
 		"chat_id": {strconv.Itoa(chatID)},
 		"text":    {"Please subscribe to continue"},
-	}, nil
+	}}, nil
 }
 
-func (s *Service) userUnsubscribe(id primitive.ObjectID, chatID int) (url.Values, error) {
-	deleteErr := s.DB.Delete(id)
-	if deleteErr != nil {
-		return nil, deleteErr
+func (s *Service) userLocationRequest(body *telegram.Update, _ db.User, chatID int) (Step, error) {
+	switch body.Message.Text {
+	case utilities.Back:
+		reply, replyErr := timeStepReply(chatID, "")
+		return Step{Reply: reply, Next: db.Subscribed}, replyErr
+	case utilities.Cancel:
+		return cancelOnboarding(chatID)
 	}
-	return url.Values{
-		"chat_id": {strconv.Itoa(chatID)},
-		"text":    {"You have unsubscribed from weather forecast"},
-	}, nil
-}
 
-func (s *Service) userLocationRequest(body *api.WebHookReqBody, chatID int, user db.User) (url.Values, error) {
-	jsonData, jsonErr := utilities.ButtonMarshal(utilities.LocationButton)
+	jsonData, jsonErr := utilities.ButtonMarshal(utilities.SubscribedMenu)
 	if jsonErr != nil {
-		return url.Values{}, fmt.Errorf("error marshaling JSON: %w", jsonErr)
-	}
-	//Left unsubscribe option in case user decided to unsubscribe at this point
-	if body.Message.Text == utilities.Unsubscribe {
-		if _, err := s.userUnsubscribe(user.ID, chatID); err != nil {
-			return url.Values{}, err
-		}
-
+		return Step{}, fmt.Errorf("error marshaling JSON: %w", jsonErr)
 	}
 
 	if !utilities.IsLocationEmpty(body.Message.Location) {
-		update := bson.D{{"$set", bson.D{
-			{"location", body.Message.Location},
-			{"subscriptionStatus", db.LocationProvided},
-		}}}
-
-		err := s.DB.Update(update, user.ID)
-		if err != nil {
-			return nil, err
+		text, set := "Location updated", bson.D{{"location", body.Message.Location}}
+		if body.Message.Venue != nil {
+			text = fmt.Sprintf("Location updated to %v", body.Message.Venue.Title)
+			set = append(set, bson.E{Key: "place", Value: body.Message.Venue.Title})
 		}
-		return url.Values{
-			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {"Location updated"},
+		return Step{
+			Reply: url.Values{
+				"chat_id":      {strconv.Itoa(chatID)},
+				"text":         {text},
+				"reply_markup": {string(jsonData)},
+			},
+			Next: db.LocationProvided,
+			Set:  set,
 		}, nil
-
 	}
 
 	if body.Message.Text != "" {
-		update := bson.D{{"$set", bson.D{
-			{"subscriptionStatus", db.LocationProvided},
-			{"city", body.Message.Text},
-		}}}
-
-		updateErr := s.DB.Update(update, user.ID)
-		if updateErr != nil {
-			return nil, updateErr
-		}
-		return url.Values{
-			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {"City updated"},
+		return Step{
+			Reply: url.Values{
+				"chat_id":      {strconv.Itoa(chatID)},
+				"text":         {"City updated"},
+				"reply_markup": {string(jsonData)},
+			},
+			Next: db.LocationProvided,
+			Set:  bson.D{{"city", body.Message.Text}},
 		}, nil
 	}
 
-	//Checking if user location can be used in weather request
-	_, weatherError := s.Weather.WeatherRequest(user)
-	if weatherError != nil {
-		return url.Values{
-			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {"invalid location provided"},
-		}, weatherError
-	}
-
-	return url.Values{
-		"chat_id":      {strconv.Itoa(chatID)},
-		"text":         {"please enter city or share location to continue\nExample: New York"},
-		"reply_markup": {string(jsonData)},
-	}, nil
+	reply, replyErr := locationStepReply(chatID, "please enter city or share location to continue\nExample: New York")
+	return Step{Reply: reply}, replyErr
 }
 
-func (s *Service) answerHandle(body *api.WebHookReqBody, chatID int, user db.User) (url.Values, error) {
+func (s *Service) answerHandle(body *telegram.Update, user db.User, chatID int) (Step, error) {
 	subscribedButtons, _ := utilities.ButtonMarshal(utilities.SubscribedMenu)
 
-	if body.Message.Text == utilities.Unsubscribe {
-		return s.userUnsubscribe(user.ID, chatID)
+	//Time can be written in words, so it is checked before the city
+	if _, timeErr := utilities.ConvertTime(body.Message.Text); timeErr == nil && utilities.IsLocationEmpty(body.Message.Location) {
+		return s.timeUpdate(body.Message.Text, chatID)
 	}
 
-	if unicode.IsDigit(rune(body.Message.Text[0])) {
-		return s.timeUpdate(body.Message.Text, user.ID, chatID)
+	first, _ := utf8.DecodeRuneInString(body.Message.Text)
+	if !utilities.IsLocationEmpty(body.Message.Location) || unicode.IsLetter(first) {
+		return s.locationUpdate(body, user, chatID)
 	}
 
-	if unicode.IsLetter(rune(body.Message.Text[0])) || !utilities.IsLocationEmpty(body.Message.Location) {
-		return s.locationUpdate(body.Message.Text, body, user, chatID)
+	if unicode.IsDigit(first) {
+		return s.timeUpdate(body.Message.Text, chatID)
 	}
 
-	return url.Values{
+	return Step{Reply: url.Values{
 		"chat_id":      {strconv.Itoa(chatID)},
 		"text":         {utilities.SubscribedOptions},
 		"reply_markup": {string(subscribedButtons)},
-	}, nil
+	}}, nil
 }
 
-func (s *Service) timeUpdate(time string, id primitive.ObjectID, chatID int) (url.Values, error) {
+func (s *Service) timeUpdate(time string, chatID int) (Step, error) {
 	userTime, timeErr := utilities.ConvertTime(time)
 	if timeErr != nil {
-		return url.Values{
+		return Step{Reply: url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {utilities.InvalidTime},
+		}}, timeErr
+	}
+
+	return Step{
+		Reply: url.Values{
 			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {"invalid time, try again.Example: 12:00"},
-		}, timeErr
+			"text":    {fmt.Sprintf("User time updated to %v UTC", userTime)},
+		},
+		Set: bson.D{{"userTime", userTime}},
+	}, nil
+}
+
+func (s *Service) locationUpdate(body *telegram.Update, user db.User, chatID int) (Step, error) {
+	return s.placeUpdate(body.Message.Text, body.Message.Location, body.Message.Venue, user, chatID)
+}
+
+// placeUpdate validates city or shared location and returns step that stores it. Venue title is stored with its location
+func (s *Service) placeUpdate(city string, location telegram.Location, venue *telegram.Venue, user db.User, chatID int) (Step, error) {
+	//Shared location replaces the city so that location is used for weather request
+	text := "Location status updated!"
+	update := bson.D{{"city", city}}
+	user.City = city
+	if !utilities.IsLocationEmpty(location) {
+		title := ""
+		if venue != nil {
+			title = venue.Title
+			text = fmt.Sprintf("Location updated to %v", title)
+		}
+		update = bson.D{{"location", location}, {"city", ""}, {"place", title}}
+		user.City = ""
+		user.Location = db.Location{Latitude: location.Latitude, Longitude: location.Longitude}
 	}
+
+	//Checking if user location can be used in weather request
+	_, weatherError := s.Weather.WeatherRequest(context.Background(), user)
+	if weatherError != nil {
+		return Step{Reply: url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {"invalid weather input provided"},
+		}}, weatherError
+	}
+
+	return Step{
+		Reply: url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {text},
+		},
+		Set: update,
+	}, nil
+}
+
+func (s *Service) swingUpdate(value string, user db.User, chatID int) (url.Values, error) {
+	threshold := 0
+	if value != utilities.Off {
+		parsed, parseErr := strconv.Atoi(value)
+		if parseErr != nil || parsed <= 0 {
+			return url.Values{
+				"chat_id": {strconv.Itoa(chatID)},
+				"text":    {"invalid threshold, try again.Example: /swing 5 or /swing off"},
+			}, nil
+		}
+		threshold = parsed
+	}
+
 	update := bson.D{{"$set", bson.D{
-		{"time", userTime},
+		{"swingThreshold", threshold},
 	}}}
 
-	updateErr := s.DB.Update(update, id)
+	updateErr := s.DB.Update(update, user.ID)
 	if updateErr != nil {
 		return nil, updateErr
 	}
+
+	text := "Temperature alerts disabled"
+	if threshold > 0 {
+		text = fmt.Sprintf("You will be alerted when temperature changes by %v° or more since yesterday", threshold)
+	}
 	return url.Values{
 		"chat_id": {strconv.Itoa(chatID)},
-		"text":    {"User time updated"},
+		"text":    {text},
 	}, nil
 }
 
-func (s *Service) locationUpdate(city string, body *api.WebHookReqBody, user db.User, chatID int) (url.Values, error) {
-	//Checking if user location can be used in weather request
-	_, weatherError := s.Weather.WeatherRequest(user)
-	if weatherError != nil {
+func (s *Service) formatUpdate(format string, user db.User, chatID int) (url.Values, error) {
+	if format == "" {
+		return menuReply(chatID, "Choose forecast format", utilities.ChoiceMenu(utilities.FormatCallback, formatOptions, user.Format))
+	}
+	if format != utilities.FormatText && format != utilities.FormatChart {
 		return url.Values{
 			"chat_id": {strconv.Itoa(chatID)},
-			"text":    {"invalid weather input provided"},
-		}, weatherError
+			"text":    {"invalid format, try again.Example: /format chart or /format text"},
+		}, nil
 	}
 
-	if !utilities.IsLocationEmpty(body.Message.Location) {
-		update := bson.D{{"$set", bson.D{
-			{"location", body.Message.Location},
-		}}}
-
-		err := s.DB.Update(update, user.ID)
-		if err != nil {
-			return nil, err
-		}
-
-	}
 	update := bson.D{{"$set", bson.D{
-		{"city", city},
+		{"format", format},
 	}}}
 
 	updateErr := s.DB.Update(update, user.ID)
 	if updateErr != nil {
 		return nil, updateErr
 	}
-
 	return url.Values{
 		"chat_id": {strconv.Itoa(chatID)},
-		"text":    {"Location status updated!"},
+		"text":    {fmt.Sprintf("Forecast format updated to %v", format)},
 	}, nil
 }
@@ -8,11 +8,11 @@ import (
 	"subscriptionbot/db"
 	"subscriptionbot/mocks"
 	"subscriptionbot/service"
+	"subscriptionbot/telegram"
 	"subscriptionbot/utilities"
 	"testing"
 	"time"
 
-	api "git.foxminded.ua/foxstudent106270/telegramapi.git"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,11 +20,15 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func requestBody(t *testing.T, text string) *api.WebHookReqBody {
-	reqBody := &api.WebHookReqBody{
-		Message: api.Message{
+func requestBody(t *testing.T, text string) *telegram.Update {
+	reqBody := &telegram.Update{
+		Message: telegram.Message{
 			Text: text,
-			Chat: api.Chat{
+			Chat: telegram.Chat{
+				ID:       358383178,
+				Username: "mopsle",
+			},
+			From: telegram.From{
 				ID:       358383178,
 				Username: "mopsle",
 			},
@@ -46,11 +50,12 @@ func TestService_Subscription(t *testing.T) {
 	weatherService := mocks.NewWeatherService(controller)
 	telegramService := mocks.NewTelegramService(controller)
 
-	tgService := service.NewService(storage, weatherService, telegramService)
+	tgService := service.NewService(storage, weatherService, telegramService, mocks.NewBotService(controller))
 
 	reqBody := requestBody(t, "user1")
 
 	newUser := db.User{
+		UserID:             reqBody.Message.From.ID,
 		Username:           reqBody.Message.Chat.Username,
 		SubscriptionStatus: int(db.NewUser),
 		UserTime:           time.Now().UTC().Round(1 * time.Second).Format("15:04"),
@@ -58,7 +63,7 @@ func TestService_Subscription(t *testing.T) {
 	}
 	updateUser := bson.D{{"$set", bson.D{
 		{"subscriptionStatus", db.TimeUpdated},
-		{"time", time.Now().UTC().Round(1 * time.Second).Format("15:04")},
+		{"userTime", time.Now().UTC().Round(1 * time.Second).Format("15:04")},
 	}}}
 	updateUserLocation := bson.D{{"$set", bson.D{
 		{"subscriptionStatus", db.LocationProvided},
@@ -67,8 +72,10 @@ func TestService_Subscription(t *testing.T) {
 
 	jsonData, jsonErr := ButtonMarshal(utilities.MenuButtons)
 	require.NoError(t, jsonErr)
-	locationData, locationErr := ButtonMarshal(utilities.LocationButton)
+	locationData, locationErr := ButtonMarshal(utilities.OnboardingLocationButtons)
 	require.NoError(t, locationErr)
+	subscribedData, subscribedErr := ButtonMarshal(utilities.SubscribedMenu)
+	require.NoError(t, subscribedErr)
 
 	tests := []struct {
 		name          string
@@ -90,7 +97,7 @@ func TestService_Subscription(t *testing.T) {
 				weather *mocks.WeatherService,
 				telegram *mocks.TelegramService,
 			) {
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{}, db.ErrNotFound)
+				storage.EXPECT().GetUser(reqBody.Message.Chat.ID).Return(db.User{}, db.ErrNotFound)
 				storage.EXPECT().Insert(&newUser).Return(nil)
 			},
 			expectedError: nil,
@@ -100,7 +107,7 @@ func TestService_Subscription(t *testing.T) {
 			text: time.Now().UTC().Format("15:04"),
 			want: url.Values{
 				"chat_id":      {strconv.Itoa(358383178)},
-				"text":         {"User time updated. Please enter city or share location to update the city for weather forecast"},
+				"text":         {fmt.Sprintf("User time updated to %v UTC.\nStep 2 of 2 ●●. Please enter city or share location to update the city for weather forecast", time.Now().UTC().Format("15:04"))},
 				"reply_markup": {string(locationData)},
 			},
 			setupMocks: func(
@@ -108,7 +115,7 @@ func TestService_Subscription(t *testing.T) {
 				weather *mocks.WeatherService,
 				telegram *mocks.TelegramService,
 			) {
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
+				storage.EXPECT().GetUser(reqBody.Message.Chat.ID).Return(db.User{
 					ID:                 primitive.ObjectID{1},
 					Username:           "mopsle",
 					SubscriptionStatus: 1,
@@ -116,6 +123,7 @@ func TestService_Subscription(t *testing.T) {
 					Location:           db.Location{},
 					City:               "",
 				}, nil)
+				storage.EXPECT().Touch(primitive.ObjectID{1}, gomock.Any()).Return(nil)
 				storage.EXPECT().UserSubscriptionStatus(primitive.ObjectID{1}).Return(int(db.Subscribed), nil)
 				storage.EXPECT().Update(updateUser, primitive.ObjectID{1})
 			},
@@ -125,15 +133,16 @@ func TestService_Subscription(t *testing.T) {
 			name: "User city updated",
 			text: "New York",
 			want: url.Values{
-				"chat_id": {strconv.Itoa(358383178)},
-				"text":    {"City updated"},
+				"chat_id":      {strconv.Itoa(358383178)},
+				"text":         {"City updated"},
+				"reply_markup": {string(subscribedData)},
 			},
 			setupMocks: func(
 				storage *mocks.MongoStorage,
 				weather *mocks.WeatherService,
 				telegram *mocks.TelegramService,
 			) {
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
+				storage.EXPECT().GetUser(reqBody.Message.Chat.ID).Return(db.User{
 					ID:                 primitive.ObjectID{1},
 					Username:           "mopsle",
 					SubscriptionStatus: 2,
@@ -141,8 +150,9 @@ func TestService_Subscription(t *testing.T) {
 					Location:           db.Location{},
 					City:               "New York",
 				}, nil)
+				storage.EXPECT().Touch(primitive.ObjectID{1}, gomock.Any()).Return(nil)
 				storage.EXPECT().UserSubscriptionStatus(primitive.ObjectID{1}).Return(int(db.TimeUpdated), nil)
-				weather.EXPECT().WeatherRequest(db.User{
+				weather.EXPECT().WeatherRequest(gomock.Any(), db.User{
 					ID:                 primitive.ObjectID{1},
 					Username:           "mopsle",
 					SubscriptionStatus: 2,
@@ -158,15 +168,16 @@ func TestService_Subscription(t *testing.T) {
 			name: "User unsubscribe",
 			text: "Unsubscribe",
 			want: url.Values{
-				"chat_id": {strconv.Itoa(358383178)},
-				"text":    {"You have unsubscribed from weather forecast"},
+				"chat_id":      {strconv.Itoa(358383178)},
+				"text":         {"You have unsubscribed from weather forecast"},
+				"reply_markup": {`{"inline_keyboard":[[{"text":"↩️ Undo","callback_data":"undo:"}]]}`},
 			},
 			setupMocks: func(
 				storage *mocks.MongoStorage,
 				weather *mocks.WeatherService,
 				telegram *mocks.TelegramService,
 			) {
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
+				storage.EXPECT().GetUser(reqBody.Message.Chat.ID).Return(db.User{
 					ID:                 primitive.ObjectID{1},
 					Username:           "mopsle",
 					SubscriptionStatus: 4,
@@ -174,8 +185,9 @@ func TestService_Subscription(t *testing.T) {
 					Location:           db.Location{},
 					City:               "New York",
 				}, nil)
+				storage.EXPECT().Touch(primitive.ObjectID{1}, gomock.Any()).Return(nil)
 				storage.EXPECT().UserSubscriptionStatus(primitive.ObjectID{1}).Return(int(db.LocationProvided), nil)
-				storage.EXPECT().Delete(primitive.ObjectID{1})
+				storage.EXPECT().Update(service.Deactivation, primitive.ObjectID{1}).Return(nil)
 			},
 			expectedError: nil,
 		},
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/phuslu/log"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 func (s *Service) Notify(ctx context.Context) {
@@ -61,10 +60,13 @@ func (s *Service) NotifySubscribers(ctx context.Context) error {
 			continue
 		}
 		currentTime := time.Now().UTC()
+		if reminderErr := s.notifyGoldenHour(sub, currentTime); reminderErr != nil {
+			log.Error().Err(reminderErr).Msgf("unable to send golden hour reminder to ChatID:%v", sub.ChatID)
+		}
 		nextTrigger := sendNextTime(currentTime, userTime)
 
 		if needtoSend(currentTime, nextTrigger, sub.ForecastSentAt) {
-			subscriber, _ := s.DB.GetUser(sub.Username)
+			subscriber, _ := s.DB.GetUser(sub.ChatID)
 			currentUserTime, currentUserTimeError := time.Parse("15:04", subscriber.UserTime)
 			if currentUserTimeError != nil {
 				log.Error().Err(currentUserTimeError)
@@ -75,11 +77,10 @@ func (s *Service) NotifySubscribers(ctx context.Context) error {
 				log.Info().Msgf("User time was changed. Using the latest one")
 			}
 			if subscriber.SubscriptionStatus == int(db.LocationProvided) {
-				forecast, _ := s.Weather.WeatherRequest(subscriber)
-				s.API.SendResponse(subscriber.ChatID, forecast)
-				s.DB.Update(bson.D{{"$set", bson.D{
-					{"forecastSentAt", nextTrigger},
-				}}}, subscriber.ID)
+				subscriber = following(travelling(subscriber, currentTime), currentTime)
+				if sendErr := s.sendForecast(ctx, subscriber, currentTime, nextTrigger); sendErr != nil {
+					log.Error().Err(sendErr).Msgf("unable to send forecast to ChatID:%v", subscriber.ChatID)
+				}
 			}
 		}
 	}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net/url"
+	"strconv"
 	"subscriptionbot/db"
 	"subscriptionbot/mocks"
+	"subscriptionbot/telegram"
+	weatherAPI "subscriptionbot/weather"
 	"testing"
 	"time"
 
-	api "git.foxminded.ua/foxstudent106270/telegramapi.git"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,11 +19,11 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func requestBody(t *testing.T, text string) *api.WebHookReqBody {
-	reqBody := &api.WebHookReqBody{
-		Message: api.Message{
+func requestBody(t *testing.T, text string) *telegram.Update {
+	reqBody := &telegram.Update{
+		Message: telegram.Message{
 			Text: text,
-			Chat: api.Chat{
+			Chat: telegram.Chat{
 				ID:       358383178,
 				Username: "elon",
 			},
@@ -34,217 +38,172 @@ func TestTickUser(t *testing.T) {
 	telegram := mocks.NewTelegramService(controller)
 	weather := mocks.NewWeatherService(controller)
 
-	tgService := NewService(storage, weather, telegram)
+	tgService := NewService(storage, weather, telegram, mocks.NewBotService(controller))
 
 	currentTime := time.Now().UTC()
 	missedTime := currentTime.Add(-3 * time.Hour)
 
-	reqBody := requestBody(t, "user1")
-	cityUpdated := bson.D{{"$set", bson.D{
-		{"city", "Toronto"},
-	}}}
-
-	newSubscriber := db.User{
-		Username:           reqBody.Message.Chat.Username,
-		SubscriptionStatus: int(db.LocationProvided),
-		UserTime:           time.Now().UTC().Round(1 * time.Second).Format("15:04"),
-		ChatID:             reqBody.Message.Chat.ID,
-		City:               "New York",
-		ForecastSentAt:     currentTime,
+	chatID := requestBody(t, "user1").Message.Chat.ID
+	userTime := currentTime.Format("15:04")
+	forecast := weatherAPI.WeatherData{
+		Weather: []weatherAPI.Weather{{Description: "clear sky"}},
+		Main:    weatherAPI.Main{Temp: 20, FeelsLike: 19},
+		Name:    "New York",
 	}
 
 	tests := []struct {
-		name       string
-		setupMocks func(storage *mocks.MongoStorage, weather *mocks.WeatherService, telegram *mocks.TelegramService)
+		name        string
+		subscribers []db.User
+		notified    []db.User
 	}{
 		{
 			name: "subscribed users",
-			setupMocks: func(
-				storage *mocks.MongoStorage,
-				weather *mocks.WeatherService,
-				telegram *mocks.TelegramService,
-			) {
-				storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return([]db.User{
-					{
-						ID:                 primitive.ObjectID{1},
-						Username:           "mopsle",
-						SubscriptionStatus: 4,
-						UserTime:           time.Now().UTC().Format("15:04"),
-						Location:           db.Location{},
-						City:               "",
-						ForecastSentAt:     missedTime,
-					},
-				}, nil)
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
-					ID:                 primitive.ObjectID{1},
-					Username:           "mopsle",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "",
-					ForecastSentAt:     missedTime,
-				}, nil).AnyTimes()
-				forecast := weather.EXPECT().WeatherRequest(db.User{
-					ID:                 primitive.ObjectID{1},
-					Username:           "mopsle",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "New York",
-					ForecastSentAt:     missedTime,
-				}).AnyTimes()
-				telegram.EXPECT().SendResponse(reqBody.Message.Chat.ID, forecast).AnyTimes()
+			subscribers: []db.User{
+				{ID: primitive.ObjectID{1}, Username: "mopsle", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "New York", ChatID: chatID + 1, ForecastSentAt: missedTime},
+			},
+			notified: []db.User{
+				{ID: primitive.ObjectID{1}, Username: "mopsle", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "New York", ChatID: chatID + 1, ForecastSentAt: missedTime},
 			},
 		},
 		{
 			name: "new subscriber",
-			setupMocks: func(
-				storage *mocks.MongoStorage,
-				weather *mocks.WeatherService,
-				telegram *mocks.TelegramService,
-			) {
-				storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return([]db.User{
-					{
-						ID:                 primitive.ObjectID{1},
-						Username:           "mopsle",
-						SubscriptionStatus: 4,
-						UserTime:           time.Now().UTC().Format("15:04"),
-						Location:           db.Location{},
-						City:               "Berlin",
-					},
-				}, nil)
-				storage.EXPECT().Insert(&newSubscriber).Return(nil).AnyTimes()
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
-					ID:                 primitive.ObjectID{3},
-					Username:           "elon",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "Berlin",
-				}, nil).AnyTimes()
-				forecast := weather.EXPECT().WeatherRequest(db.User{
-					ID:                 primitive.ObjectID{3},
-					Username:           "elon",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "Berlin",
-				}).AnyTimes()
-				telegram.EXPECT().SendResponse(reqBody.Message.Chat.ID, forecast).AnyTimes()
+			subscribers: []db.User{
+				{ID: primitive.ObjectID{3}, Username: "elon", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "Berlin", ChatID: chatID + 3},
+			},
+			notified: []db.User{
+				{ID: primitive.ObjectID{3}, Username: "elon", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "Berlin", ChatID: chatID + 3},
 			},
 		},
 		{
 			name: "city added",
-			setupMocks: func(
-				storage *mocks.MongoStorage,
-				weather *mocks.WeatherService,
-				telegram *mocks.TelegramService,
-			) {
-				storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return([]db.User{
-					{
-						ID:                 primitive.ObjectID{1},
-						Username:           "mopsle",
-						SubscriptionStatus: 4,
-						UserTime:           time.Now().UTC().Format("15:04"),
-						Location:           db.Location{},
-						City:               "New York",
-					},
-				}, nil)
-				storage.EXPECT().Update(cityUpdated, primitive.ObjectID{1}).AnyTimes()
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
-					ID:                 primitive.ObjectID{1},
-					Username:           "mopsle",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "Toronto",
-				}, nil).AnyTimes()
-				forecast := weather.EXPECT().WeatherRequest(db.User{
-					ID:                 primitive.ObjectID{1},
-					Username:           "mopsle",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "Toronto",
-				}).AnyTimes()
-				telegram.EXPECT().SendResponse(reqBody.Message.Chat.ID, forecast).AnyTimes()
+			subscribers: []db.User{
+				{ID: primitive.ObjectID{1}, Username: "mopsle", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "New York", ChatID: chatID + 1},
+			},
+			notified: []db.User{
+				{ID: primitive.ObjectID{1}, Username: "mopsle", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "Toronto", ChatID: chatID + 1},
 			},
 		},
 		{
 			name: "missed and not missed forecast",
-			setupMocks: func(
-				storage *mocks.MongoStorage,
-				weather *mocks.WeatherService,
-				telegram *mocks.TelegramService,
-			) {
-				storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return([]db.User{
-					{
-						ID:                 primitive.ObjectID{1},
-						Username:           "mopsle",
-						SubscriptionStatus: 4,
-						UserTime:           time.Now().UTC().Format("15:04"),
-						Location:           db.Location{},
-						City:               "New York",
-						ForecastSentAt:     missedTime,
-					},
-					{
-						ID:                 primitive.ObjectID{2},
-						Username:           "Maria",
-						SubscriptionStatus: 4,
-						UserTime:           time.Now().UTC().Format("15:04"),
-						Location:           db.Location{},
-						City:               "London",
-					},
-				}, nil)
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
-					ID:                 primitive.ObjectID{1},
-					Username:           "mopsle",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "New York",
-					ForecastSentAt:     missedTime,
-				}, nil).AnyTimes()
-				forecastUser := weather.EXPECT().WeatherRequest(db.User{
-					ID:                 primitive.ObjectID{1},
-					Username:           "mopsle",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "New York",
-					ForecastSentAt:     missedTime,
-				}).AnyTimes()
-				telegram.EXPECT().SendResponse(reqBody.Message.Chat.ID, forecastUser).AnyTimes()
-
-				storage.EXPECT().GetUser(reqBody.Message.Chat.Username).Return(db.User{
-					ID:                 primitive.ObjectID{2},
-					Username:           "Maria",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "London",
-				}, nil).AnyTimes()
-				forecast := weather.EXPECT().WeatherRequest(db.User{
-					ID:                 primitive.ObjectID{2},
-					Username:           "Maria",
-					SubscriptionStatus: 4,
-					UserTime:           time.Now().UTC().Format("15:04"),
-					Location:           db.Location{},
-					City:               "London",
-				}).AnyTimes()
-				telegram.EXPECT().SendResponse(reqBody.Message.Chat.ID, forecast).AnyTimes()
+			subscribers: []db.User{
+				{ID: primitive.ObjectID{1}, Username: "mopsle", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "New York", ChatID: chatID + 1, ForecastSentAt: missedTime},
+				{ID: primitive.ObjectID{2}, Username: "Maria", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "London", ChatID: chatID + 2, ForecastSentAt: sendNextTime(currentTime, currentTime).Add(time.Minute)},
+			},
+			notified: []db.User{
+				{ID: primitive.ObjectID{1}, Username: "mopsle", SubscriptionStatus: int(db.LocationProvided), UserTime: userTime, City: "New York", ChatID: chatID + 1, ForecastSentAt: missedTime},
 			},
 		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			tc.setupMocks(storage, weather, telegram)
+			storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return(tc.subscribers, nil)
+			for _, user := range tc.notified {
+				storage.EXPECT().GetUser(user.ChatID).Return(user, nil)
+				weather.EXPECT().CurrentWeather(gomock.Any(), user).Return(forecast, nil)
+				telegram.EXPECT().SendResponse(user.ChatID, url.Values{
+					"chat_id": {strconv.Itoa(user.ChatID)},
+					"text":    {weatherAPI.FormatForecast(forecast)},
+				}).Return(nil)
+				storage.EXPECT().Update(gomock.Any(), user.ID).Return(nil)
+			}
+
 			err := tgService.NotifySubscribers(context.Background())
 			require.NoError(t, err)
 		})
 	}
 }
 
+func TestNotifySubscribers_failedForecast(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	telegram := mocks.NewTelegramService(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, telegram, mocks.NewBotService(controller))
+
+	currentTime := time.Now().UTC()
+	user := db.User{ID: primitive.ObjectID{1}, SubscriptionStatus: int(db.LocationProvided), UserTime: currentTime.Format("15:04"), City: "Atlantis", ChatID: 358383178, ForecastSentAt: currentTime.Add(-3 * time.Hour)}
+	forecast := weatherAPI.WeatherData{Weather: []weatherAPI.Weather{{Description: "clear sky"}}, Name: "Kyiv"}
+
+	tests := []struct {
+		name       string
+		setupMocks func()
+	}{
+		{
+			name: "city not found",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), user).Return(weatherAPI.WeatherData{}, weatherAPI.ErrNotFound)
+			},
+		},
+		{
+			name: "bot blocked by user",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), user).Return(forecast, nil)
+				telegram.EXPECT().SendResponse(user.ChatID, gomock.Any()).Return(errors.New("Forbidden: bot was blocked by the user"))
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempted db.User
+			storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return([]db.User{user}, nil)
+			storage.EXPECT().GetUser(user.ChatID).Return(user, nil)
+			tc.setupMocks()
+			storage.EXPECT().Update(gomock.Any(), user.ID).DoAndReturn(func(update bson.D, _ primitive.ObjectID) error {
+				attempted = user
+				attempted.ForecastSentAt = update[0].Value.(bson.D)[0].Value.(time.Time)
+				return nil
+			})
+			require.NoError(t, tgService.NotifySubscribers(context.Background()))
+			assert.True(t, attempted.ForecastSentAt.After(currentTime), "attempt is recorded")
+
+			//Next tick doesn't retry the forecast
+			storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return([]db.User{attempted}, nil)
+			require.NoError(t, tgService.NotifySubscribers(context.Background()))
+		})
+	}
+}
+
+func TestNotifySubscribers_trip(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	telegram := mocks.NewTelegramService(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, telegram, mocks.NewBotService(controller))
+
+	currentTime := time.Now().UTC()
+	today := currentTime.Truncate(24 * time.Hour)
+	user := db.User{ID: primitive.ObjectID{1}, SubscriptionStatus: int(db.LocationProvided), UserTime: currentTime.Format("15:04"), City: "Kyiv", ChatID: 358383178}
+
+	tests := []struct {
+		name string
+		trip db.Trip
+		want string
+	}{
+		{name: "day before trip", trip: db.Trip{City: "Paris", From: today.AddDate(0, 0, 1), To: today.AddDate(0, 0, 2)}, want: "Kyiv"},
+		{name: "first day of trip", trip: db.Trip{City: "Paris", From: today, To: today.AddDate(0, 0, 1)}, want: "Paris"},
+		{name: "last day of trip", trip: db.Trip{City: "Paris", From: today.AddDate(0, 0, -1), To: today}, want: "Paris"},
+		{name: "day after trip", trip: db.Trip{City: "Paris", From: today.AddDate(0, 0, -2), To: today.AddDate(0, 0, -1)}, want: "Kyiv"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			subscriber := user
+			subscriber.Trips = []db.Trip{tc.trip}
+			forecast := weatherAPI.WeatherData{Weather: []weatherAPI.Weather{{Description: "clear sky"}}, Name: tc.want}
+
+			storage.EXPECT().GetSubscribedUsers(gomock.Any()).Return([]db.User{subscriber}, nil)
+			storage.EXPECT().GetUser(user.ChatID).Return(subscriber, nil)
+			weather.EXPECT().CurrentWeather(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user db.User) (weatherAPI.WeatherData, error) {
+				assert.Equal(t, tc.want, user.City)
+				return forecast, nil
+			})
+			telegram.EXPECT().SendResponse(user.ChatID, gomock.Any()).Return(nil)
+			storage.EXPECT().Update(gomock.Any(), user.ID).Return(nil)
+
+			require.NoError(t, tgService.NotifySubscribers(context.Background()))
+		})
+	}
+}
+
 func Test_needtoSend(t *testing.T) {
 	timeNow := time.Now().UTC()
 
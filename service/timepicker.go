@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/utilities"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const hourPickerText = "Choose hour of daily forecast, UTC"
+
+// timePickerCallback shows hour grid of time picker
+func (s *Service) timePickerCallback(_ string, _ db.User, state db.SubscriptionStatus, chatID int) (Step, error) {
+	if state == db.Subscribed {
+		reply, replyErr := timeStepReply(chatID, "")
+		return Step{Reply: reply}, replyErr
+	}
+
+	reply, replyErr := menuReply(chatID, hourPickerText, utilities.HourPicker())
+	return Step{Reply: reply}, replyErr
+}
+
+// hourCallback shows minute grid for chosen hour
+func (s *Service) hourCallback(hour string, _ db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	parsed, parseErr := strconv.Atoi(hour)
+	if parseErr != nil || parsed < 0 || parsed > 23 {
+		reply, replyErr := menuReply(chatID, hourPickerText, utilities.HourPicker())
+		return Step{Reply: reply}, replyErr
+	}
+
+	reply, replyErr := menuReply(chatID, fmt.Sprintf("Choose time of daily forecast, UTC. Hour %02d", parsed), utilities.MinutePicker(fmt.Sprintf("%02d", parsed)))
+	return Step{Reply: reply}, replyErr
+}
+
+// minuteCallback stores picked time. While time is requested it moves the flow to location request
+func (s *Service) minuteCallback(value string, _ db.User, state db.SubscriptionStatus, chatID int) (Step, error) {
+	if state == db.NewUser {
+		return subscribeFirst(chatID), nil
+	}
+
+	userTime, timeErr := utilities.ConvertTime(value)
+	if timeErr != nil {
+		reply, replyErr := menuReply(chatID, "invalid time, try again. "+hourPickerText, utilities.HourPicker())
+		return Step{Reply: reply}, replyErr
+	}
+
+	text := fmt.Sprintf("Forecast time set to %v UTC", userTime)
+	if state != db.Subscribed {
+		reply, replyErr := menuReply(chatID, text, backToSettings(utilities.InlineKeyboardMarkup{}))
+		return Step{Reply: reply, Set: bson.D{{"userTime", userTime}}}, replyErr
+	}
+	follow, followErr := locationStepReply(chatID, "")
+	return Step{
+		Reply: url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {text},
+		},
+		Next:   db.TimeUpdated,
+		Set:    bson.D{{"userTime", userTime}},
+		Follow: follow,
+	}, followErr
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStateMachine_HandleCallback_timePicker(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	telegramService := mocks.NewTelegramService(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), telegramService, mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}}
+
+	tests := []struct {
+		name       string
+		data       string
+		state      db.SubscriptionStatus
+		want       string
+		wantMarkup string
+		setupMocks func()
+	}{
+		{
+			name:       "hour picked",
+			data:       "hour:7",
+			state:      db.LocationProvided,
+			want:       "Choose time of daily forecast, UTC. Hour 07",
+			wantMarkup: `{"inline_keyboard":[[{"text":"07:00","callback_data":"minute:07:00"},{"text":"07:05","callback_data":"minute:07:05"},{"text":"07:10","callback_data":"minute:07:10"},{"text":"07:15","callback_data":"minute:07:15"}],[{"text":"07:20","callback_data":"minute:07:20"},{"text":"07:25","callback_data":"minute:07:25"},{"text":"07:30","callback_data":"minute:07:30"},{"text":"07:35","callback_data":"minute:07:35"}],[{"text":"07:40","callback_data":"minute:07:40"},{"text":"07:45","callback_data":"minute:07:45"},{"text":"07:50","callback_data":"minute:07:50"},{"text":"07:55","callback_data":"minute:07:55"}],[{"text":"« Hours","callback_data":"time:"}]]}`,
+			setupMocks: func() {},
+		},
+		{
+			name:       "invalid hour",
+			data:       "hour:25",
+			state:      db.LocationProvided,
+			want:       "Choose hour of daily forecast, UTC",
+			setupMocks: func() {},
+		},
+		{
+			name:  "time picked when subscribed",
+			data:  "minute:07:30",
+			state: db.LocationProvided,
+			want:  "Forecast time set to 07:30 UTC",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"userTime", "07:30"}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:  "time picked during onboarding",
+			data:  "minute:19:05",
+			state: db.Subscribed,
+			want:  "Forecast time set to 19:05 UTC",
+			setupMocks: func() {
+				gomock.InOrder(
+					storage.EXPECT().Update(bson.D{{"$set", bson.D{
+						{"subscriptionStatus", db.TimeUpdated},
+						{"userTime", "19:05"},
+					}}}, user.ID).Return(nil),
+					telegramService.EXPECT().SendResponse(358383178, gomock.Any()).Return(nil),
+				)
+			},
+		},
+		{
+			name:       "time picked before subscribe",
+			data:       "minute:07:30",
+			state:      db.NewUser,
+			want:       "Please subscribe to continue",
+			setupMocks: func() {},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			got, err := subscriptionFlow.HandleCallback(tgService, tc.data, user, tc.state, 358383178)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+			if tc.wantMarkup != "" {
+				assert.JSONEq(t, tc.wantMarkup, got.Get("reply_markup"))
+			}
+		})
+	}
+}
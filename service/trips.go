@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"subscriptionbot/db"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxTrips user can plan
+const maxTrips = 5
+
+const tripsUsage = "Example: /trip Paris 2024-05-01 2024-05-05, /trip cancel Paris, /trip list"
+
+// tripCommand handles /trip <city> <from> <to>, /trip cancel <city> and /trip list
+func (s *Service) tripCommand(args string, user db.User, chatID int) (url.Values, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	action, rest, _ := strings.Cut(args, " ")
+
+	switch strings.ToLower(action) {
+	case "", "list":
+		return placesReply(chatID, formatTrips(upcomingTrips(user.Trips, today))), nil
+	case "cancel":
+		return s.tripCancel(strings.TrimSpace(rest), user, today, chatID)
+	default:
+		return s.tripAdd(args, user, today, chatID)
+	}
+}
+
+// tripAdd plans a trip. City can have several words, dates are the last two words
+func (s *Service) tripAdd(args string, user db.User, today time.Time, chatID int) (url.Values, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 3 {
+		return placesReply(chatID, fmt.Sprintf("city and dates are required. %v", tripsUsage)), nil
+	}
+	city := strings.Join(fields[:len(fields)-2], " ")
+	from, fromErr := time.Parse(dateLayout, fields[len(fields)-2])
+	to, toErr := time.Parse(dateLayout, fields[len(fields)-1])
+	if fromErr != nil || toErr != nil {
+		return placesReply(chatID, fmt.Sprintf("invalid dates, use year-month-day. %v", tripsUsage)), nil
+	}
+	if to.Before(from) {
+		return placesReply(chatID, "trip can't end before it starts"), nil
+	}
+	if to.Before(today) {
+		return placesReply(chatID, "trip is already over"), nil
+	}
+
+	trips := upcomingTrips(user.Trips, today)
+	for _, trip := range trips {
+		if !from.After(trip.To) && !to.Before(trip.From) {
+			return placesReply(chatID, fmt.Sprintf("trip overlaps your trip to %v %v", trip.City, formatDates(trip))), nil
+		}
+	}
+	if len(trips) >= maxTrips {
+		return placesReply(chatID, fmt.Sprintf("you can plan up to %v trips. Cancel one to add another", maxTrips)), nil
+	}
+
+	//Checking if city can be used in weather request
+	if _, weatherErr := s.Weather.CurrentWeather(context.Background(), tripUser(user, db.Trip{City: city})); weatherErr != nil {
+		return placesReply(chatID, fmt.Sprintf("unable to find weather for %v", city)), nil
+	}
+
+	trips = append(trips, db.Trip{City: city, From: from, To: to})
+	sort.Slice(trips, func(i, j int) bool { return trips[i].From.Before(trips[j].From) })
+	if updateErr := s.updateTrips(user, trips); updateErr != nil {
+		return nil, updateErr
+	}
+
+	return placesReply(chatID, fmt.Sprintf("Trip to %v planned. Forecast will be for %v on these dates\n\n%v", city, city, formatTrips(trips))), nil
+}
+
+// tripCancel removes upcoming trips to the city
+func (s *Service) tripCancel(city string, user db.User, today time.Time, chatID int) (url.Values, error) {
+	var (
+		trips     []db.Trip
+		cancelled bool
+	)
+	for _, trip := range upcomingTrips(user.Trips, today) {
+		if strings.EqualFold(trip.City, city) {
+			cancelled = true
+			continue
+		}
+		trips = append(trips, trip)
+	}
+	if !cancelled {
+		return placesReply(chatID, fmt.Sprintf("trip to %v not found", city)), nil
+	}
+
+	if updateErr := s.updateTrips(user, trips); updateErr != nil {
+		return nil, updateErr
+	}
+	return placesReply(chatID, fmt.Sprintf("Trip to %v cancelled\n\n%v", city, formatTrips(trips))), nil
+}
+
+func (s *Service) updateTrips(user db.User, trips []db.Trip) error {
+	if trips == nil {
+		trips = []db.Trip{}
+	}
+
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"trips", trips},
+	}}}, user.ID)
+}
+
+// travelling returns user with trip destination as location if forecast is sent during a trip
+func travelling(user db.User, at time.Time) db.User {
+	day := at.UTC().Truncate(24 * time.Hour)
+	for _, trip := range user.Trips {
+		if !day.Before(trip.From) && !day.After(trip.To) {
+			return tripUser(user, trip)
+		}
+	}
+	return user
+}
+
+// tripUser returns user with city of the trip for weather requests
+func tripUser(user db.User, trip db.Trip) db.User {
+	user.City = trip.City
+	user.Location = db.Location{}
+	user.Place = ""
+	return user
+}
+
+// upcomingTrips returns trips that are not over yet
+func upcomingTrips(trips []db.Trip, today time.Time) []db.Trip {
+	var upcoming []db.Trip
+	for _, trip := range trips {
+		if !trip.To.Before(today) {
+			upcoming = append(upcoming, trip)
+		}
+	}
+	return upcoming
+}
+
+func formatTrips(trips []db.Trip) string {
+	if len(trips) == 0 {
+		return fmt.Sprintf("You have no upcoming trips. %v", tripsUsage)
+	}
+
+	lines := []string{"✈️Upcoming trips:"}
+	for _, trip := range trips {
+		lines = append(lines, fmt.Sprintf("%v %v", trip.City, formatDates(trip)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatDates(trip db.Trip) string {
+	return fmt.Sprintf("%v – %v", trip.From.Format(dateLayout), trip.To.Format(dateLayout))
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestService_tripCommand(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	day := func(days int) time.Time { return today.AddDate(0, 0, days) }
+	date := func(days int) string { return day(days).Format(dateLayout) }
+	rome := db.Trip{City: "Rome", From: day(10), To: day(12)}
+	user := db.User{
+		ID:     primitive.ObjectID{1},
+		City:   "Kyiv",
+		ChatID: 358383178,
+		Trips:  []db.Trip{{City: "Lviv", From: day(-5), To: day(-2)}, rome},
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		want       string
+		setupMocks func()
+	}{
+		{
+			name: "list upcoming trips",
+			args: "",
+			want: "✈️Upcoming trips:\nRome " + date(10) + " – " + date(12),
+		},
+		{
+			name: "plan trip",
+			args: "New York " + date(2) + " " + date(4),
+			want: "Trip to New York planned. Forecast will be for New York on these dates\n\n✈️Upcoming trips:\nNew York " + date(2) + " – " + date(4) + "\nRome " + date(10) + " – " + date(12),
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), tripUser(user, db.Trip{City: "New York"})).Return(weatherAPI.WeatherData{}, nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"trips", []db.Trip{
+					{City: "New York", From: day(2), To: day(4)},
+					rome,
+				}}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name: "overlapping trip",
+			args: "Paris " + date(12) + " " + date(14),
+			want: "trip overlaps your trip to Rome " + date(10) + " – " + date(12),
+		},
+		{
+			name: "trip ends before it starts",
+			args: "Paris " + date(4) + " " + date(2),
+			want: "trip can't end before it starts",
+		},
+		{
+			name: "missing dates",
+			args: "Paris tomorrow",
+			want: "city and dates are required. " + tripsUsage,
+		},
+		{
+			name: "invalid dates",
+			args: "Paris May-1 May-5",
+			want: "invalid dates, use year-month-day. " + tripsUsage,
+		},
+		{
+			name: "unknown city",
+			args: "Nowhere " + date(2) + " " + date(4),
+			want: "unable to find weather for Nowhere",
+			setupMocks: func() {
+				weather.EXPECT().CurrentWeather(gomock.Any(), gomock.Any()).Return(weatherAPI.WeatherData{}, weatherAPI.ErrNotFound)
+			},
+		},
+		{
+			name: "cancel trip",
+			args: "cancel rome",
+			want: "Trip to rome cancelled\n\nYou have no upcoming trips. " + tripsUsage,
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"trips", []db.Trip{}}}}}, user.ID).Return(nil)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.setupMocks != nil {
+				tc.setupMocks()
+			}
+
+			got, err := tgService.tripCommand(tc.args, user, user.ChatID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
+
+func TestTravelling(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	user := db.User{
+		Location: db.Location{Latitude: 50.45, Longitude: 30.52},
+		Trips:    []db.Trip{{City: "Paris", From: from, To: from.AddDate(0, 0, 4)}},
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{name: "before trip", at: from.Add(-time.Hour), want: ""},
+		{name: "first day", at: from.Add(8 * time.Hour), want: "Paris"},
+		{name: "last day", at: from.AddDate(0, 0, 4).Add(20 * time.Hour), want: "Paris"},
+		{name: "back home", at: from.AddDate(0, 0, 5), want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := travelling(user, tc.at)
+			assert.Equal(t, tc.want, got.City)
+			if tc.want == "" {
+				assert.Equal(t, user.Location, got.Location)
+			}
+		})
+	}
+}
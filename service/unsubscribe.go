@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+	"time"
+
+	"github.com/phuslu/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RetentionConfig struct for unsubscribed users and abandoned sign ups
+type RetentionConfig struct {
+	UndoPeriod      time.Duration `env:"UNDO_PERIOD" envDefault:"15m"`
+	Retention       time.Duration `env:"RETENTION_PERIOD" envDefault:"720h"`
+	PurgeInterval   time.Duration `env:"PURGE_INTERVAL" envDefault:"1h"`
+	IdleTimeout     time.Duration `env:"IDLE_TIMEOUT" envDefault:"24h"`
+	AbandonedPeriod time.Duration `env:"ABANDONED_PERIOD" envDefault:"168h"`
+}
+
+// userUnsubscribe marks user inactive so that settings can be restored with Undo or on resubscribe
+func (s *Service) userUnsubscribe(_ string, user db.User, chatID int) (url.Values, error) {
+	if deactivateErr := s.deactivate(user, time.Now().UTC()); deactivateErr != nil {
+		return nil, deactivateErr
+	}
+
+	undo := utilities.InlineKeyboardMarkup{InlineKeyboard: [][]utilities.InlineKeyboardButton{
+		{{Text: "↩️ Undo", CallbackData: utilities.CallbackData(utilities.UndoCallback, "")}},
+	}}
+	return menuReply(chatID, "You have unsubscribed from weather forecast", undo)
+}
+
+// undoCallback restores subscription if Undo is pressed within undo period
+func (s *Service) undoCallback(_ string, user db.User, _ db.SubscriptionStatus, chatID int) (Step, error) {
+	if !user.Inactive {
+		return Step{Reply: textReply(chatID, "You are subscribed to weather forecast")}, nil
+	}
+	if time.Since(user.UnsubscribedAt) > s.Retention.UndoPeriod {
+		return Step{Reply: textReply(chatID, "Undo is no longer available. Press Subscribe to get weather forecast again")}, nil
+	}
+
+	return Step{
+		Reply: textReply(chatID, "Subscription restored with your previous settings"),
+		Set:   bson.D{{"inactive", false}},
+	}, nil
+}
+
+// inactiveUser handles messages of unsubscribed user. Subscribe restores previous settings
+// or resumes onboarding at the step user left it
+func (s *Service) inactiveUser(body *telegram.Update, user db.User, chatID int) (url.Values, error) {
+	if body.Message.Text != utilities.Subscribe {
+		jsonData, jsonErr := utilities.ButtonMarshal(utilities.MenuButtons)
+		if jsonErr != nil {
+			return nil, fmt.Errorf("error marshaling JSON: %w", jsonErr)
+		}
+		return url.Values{
+			"chat_id":      {strconv.Itoa(chatID)},
+			"text":         {"You have unsubscribed from weather forecast. Press Subscribe to get it again"},
+			"reply_markup": {string(jsonData)},
+		}, nil
+	}
+
+	if updateErr := s.DB.Update(bson.D{{"$set", bson.D{{"inactive", false}}}}, user.ID); updateErr != nil {
+		return nil, updateErr
+	}
+	switch db.SubscriptionStatus(user.SubscriptionStatus) {
+	case db.NewUser:
+		return subscriptionFlow.Handle(s, body, user, db.NewUser, chatID)
+	case db.Subscribed:
+		return timeStepReply(chatID, "Welcome back!")
+	case db.TimeUpdated:
+		return locationStepReply(chatID, "Welcome back!")
+	}
+
+	user.Inactive = false
+	return menuReply(chatID, fmt.Sprintf("Welcome back! Your previous settings are restored\n\n%v", formatSettings(user)), settingsKeyboard())
+}
+
+// deactivate marks user inactive since given time
+func (s *Service) deactivate(user db.User, at time.Time) error {
+	return s.DB.Update(bson.D{{"$set", bson.D{
+		{"inactive", true},
+		{"unsubscribedAt", at},
+	}}}, user.ID)
+}
+
+// Purge periodically deletes users who unsubscribed longer than retention period ago
+// and users who didn't complete subscription within abandoned period. Purge is disabled if interval isn't positive
+func (s *Service) Purge(ctx context.Context) {
+	if s.Retention.PurgeInterval <= 0 {
+		log.Warn().Msgf("Purge of unsubscribed users is disabled, purge interval is %v", s.Retention.PurgeInterval)
+		return
+	}
+
+	ticker := time.NewTicker(s.Retention.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now().UTC()
+			purged, purgeErr := s.DB.PurgeInactive(ctx, now.Add(-s.Retention.Retention))
+			if purgeErr != nil {
+				log.Error().Err(purgeErr).Msg("unable to purge unsubscribed users")
+			} else if purged > 0 {
+				log.Info().Msgf("Purged %v unsubscribed users", purged)
+			}
+
+			abandoned, abandonedErr := s.DB.PurgeAbandoned(ctx, now.Add(-s.Retention.AbandonedPeriod))
+			if abandonedErr != nil {
+				log.Error().Err(abandonedErr).Msg("unable to purge abandoned sign ups")
+			} else if abandoned > 0 {
+				log.Info().Msgf("Purged %v abandoned sign ups", abandoned)
+			}
+		}
+	}
+}
+
+// textReply returns reply with text only
+func textReply(chatID int, text string) url.Values {
+	return url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"text":    {text},
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStateMachine_HandleCallback_undo(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+
+	tests := []struct {
+		name       string
+		user       db.User
+		want       string
+		setupMocks func()
+	}{
+		{
+			name: "within undo period",
+			user: db.User{ID: primitive.ObjectID{1}, Inactive: true, UnsubscribedAt: time.Now().Add(-time.Minute)},
+			want: "Subscription restored with your previous settings",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"inactive", false}}}}, primitive.ObjectID{1}).Return(nil)
+			},
+		},
+		{
+			name:       "undo period is over",
+			user:       db.User{ID: primitive.ObjectID{1}, Inactive: true, UnsubscribedAt: time.Now().Add(-time.Hour)},
+			want:       "Undo is no longer available. Press Subscribe to get weather forecast again",
+			setupMocks: func() {},
+		},
+		{
+			name:       "already restored",
+			user:       db.User{ID: primitive.ObjectID{1}},
+			want:       "You are subscribed to weather forecast",
+			setupMocks: func() {},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			got, err := subscriptionFlow.HandleCallback(tgService, "undo:", tc.user, db.LocationProvided, 358383178)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get("text"))
+		})
+	}
+}
+
+func TestService_AddSubscription_inactive(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}, ChatID: 358383178, Inactive: true, SubscriptionStatus: int(db.LocationProvided), UserTime: "07:30", City: "Kyiv"}
+
+	t.Run("message while unsubscribed", func(t *testing.T) {
+		storage.EXPECT().GetUser(user.ChatID).Return(user, nil)
+		storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+
+		got, err := tgService.AddSubscription(requestBody(t, "Kyiv"), user.ChatID)
+		require.NoError(t, err)
+		assert.Equal(t, "You have unsubscribed from weather forecast. Press Subscribe to get it again", got.Get("text"))
+	})
+
+	t.Run("resubscribe restores settings", func(t *testing.T) {
+		storage.EXPECT().GetUser(user.ChatID).Return(user, nil)
+		storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+		storage.EXPECT().Update(bson.D{{"$set", bson.D{{"inactive", false}}}}, user.ID).Return(nil)
+
+		got, err := tgService.AddSubscription(requestBody(t, "Subscribe"), user.ChatID)
+		require.NoError(t, err)
+		assert.Equal(t, "Welcome back! Your previous settings are restored\n\n⚙️Your settings\n🕖Delivery time: 07:30\n🌐Time zone: UTC\n📍Location: Kyiv\n📏Units: default\n🗣Language: English\n🖼Format: text\n🔔Alerts: off", got.Get("text"))
+	})
+
+	onboarding := []struct {
+		name  string
+		state db.SubscriptionStatus
+		want  string
+	}{
+		{name: "resubscribe during time step", state: db.Subscribed, want: "Welcome back!\nStep 1 of 2 ●○"},
+		{name: "resubscribe during location step", state: db.TimeUpdated, want: "Welcome back!\nStep 2 of 2 ●●"},
+	}
+	for _, tc := range onboarding {
+		t.Run(tc.name, func(t *testing.T) {
+			unfinished := user
+			unfinished.SubscriptionStatus = int(tc.state)
+			storage.EXPECT().GetUser(user.ChatID).Return(unfinished, nil)
+			storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+			storage.EXPECT().Update(bson.D{{"$set", bson.D{{"inactive", false}}}}, user.ID).Return(nil)
+
+			got, err := tgService.AddSubscription(requestBody(t, "Subscribe"), user.ChatID)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(got.Get("text"), tc.want), got.Get("text"))
+			assert.NotContains(t, got.Get("reply_markup"), "settings:")
+		})
+	}
+}
+
+func TestService_Purge(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	tgService := NewService(storage, mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	tgService.Retention = RetentionConfig{Retention: 24 * time.Hour, PurgeInterval: 10 * time.Millisecond, AbandonedPeriod: 48 * time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	storage.EXPECT().PurgeInactive(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+		assert.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Second)
+		return 1, nil
+	}).MinTimes(1)
+	storage.EXPECT().PurgeAbandoned(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+		assert.WithinDuration(t, time.Now().Add(-48*time.Hour), before, time.Second)
+		cancel()
+		return 1, nil
+	}).MinTimes(1)
+
+	tgService.Purge(ctx)
+}
+
+func TestService_Purge_disabled(t *testing.T) {
+	controller := gomock.NewController(t)
+	tgService := NewService(mocks.NewMongoStorage(controller), mocks.NewWeatherService(controller), mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+
+	for _, interval := range []time.Duration{0, -time.Hour} {
+		tgService.Retention.PurgeInterval = interval
+		assert.NotPanics(t, func() { tgService.Purge(context.Background()) })
+	}
+}
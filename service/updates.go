@@ -0,0 +1,104 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"subscriptionbot/db"
+	"subscriptionbot/telegram"
+	"subscriptionbot/utilities"
+)
+
+// UpdateKind is a kind of message received from a chat
+type UpdateKind int
+
+// Kinds of messages. Unknown is a message bot can't read, like a poll or a dice
+const (
+	UnknownUpdate UpdateKind = iota
+	TextUpdate
+	CommandUpdate
+	LocationUpdate
+	VenueUpdate
+	ContactUpdate
+	MediaUpdate
+	ServiceUpdate
+)
+
+// updateKindNames for logs
+var updateKindNames = map[UpdateKind]string{
+	UnknownUpdate:  "unknown",
+	TextUpdate:     "text",
+	CommandUpdate:  "command",
+	LocationUpdate: "location",
+	VenueUpdate:    "venue",
+	ContactUpdate:  "contact",
+	MediaUpdate:    "media",
+	ServiceUpdate:  "service",
+}
+
+func (k UpdateKind) String() string {
+	return updateKindNames[k]
+}
+
+// ClassifyMessage returns kind of the message. Venue is checked before location as venue messages carry both
+func ClassifyMessage(message telegram.Message) UpdateKind {
+	switch {
+	case message.IsService():
+		return ServiceUpdate
+	case message.Venue != nil:
+		return VenueUpdate
+	case !utilities.IsLocationEmpty(message.Location):
+		return LocationUpdate
+	case message.Contact != nil:
+		return ContactUpdate
+	case message.HasMedia():
+		return MediaUpdate
+	case strings.HasPrefix(message.Text, "/"):
+		return CommandUpdate
+	case strings.TrimSpace(message.Text) != "":
+		return TextUpdate
+	default:
+		return UnknownUpdate
+	}
+}
+
+// isSupported reports if kind of message can be handled by subscription flow
+func isSupported(kind UpdateKind) bool {
+	return kind == TextUpdate || kind == CommandUpdate || kind == LocationUpdate || kind == VenueUpdate
+}
+
+// serviceMessage handles chat notifications. Only group upgrade to supergroup changes subscription
+func (s *Service) serviceMessage(message telegram.Message) (url.Values, error) {
+	if message.MigrateToChatID != 0 {
+		return nil, s.chatMigrated(message)
+	}
+	return nil, nil
+}
+
+// unsupportedReply explains which input is expected instead of a message bot can't read
+func unsupportedReply(kind UpdateKind, state db.SubscriptionStatus, chatID int) (url.Values, error) {
+	intro := "Sorry, I can't read this kind of message."
+	switch kind {
+	case ContactUpdate:
+		intro = "Contacts can't be used to set up forecast."
+	case MediaUpdate:
+		intro = "Sorry, I can't read photos, stickers, voice messages or files."
+	}
+
+	switch state {
+	case db.NewUser:
+		return url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {intro + " Please subscribe to continue"},
+		}, nil
+	case db.Subscribed:
+		return timeStepReply(chatID, intro)
+	case db.TimeUpdated:
+		return locationStepReply(chatID, intro)
+	default:
+		return url.Values{
+			"chat_id": {strconv.Itoa(chatID)},
+			"text":    {intro + " Send a city, time like 07:30 or share your location. /help lists all commands"},
+		}, nil
+	}
+}
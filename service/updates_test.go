@@ -0,0 +1,123 @@
+package service
+
+import (
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	"subscriptionbot/telegram"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestClassifyMessage(t *testing.T) {
+	kyiv := telegram.Location{Latitude: 50.45, Longitude: 30.52}
+	tests := []struct {
+		name    string
+		message telegram.Message
+		want    UpdateKind
+	}{
+		{name: "text", message: telegram.Message{Text: "Kyiv"}, want: TextUpdate},
+		{name: "command", message: telegram.Message{Text: "/time 07:30"}, want: CommandUpdate},
+		{name: "location", message: telegram.Message{Location: kyiv}, want: LocationUpdate},
+		{name: "venue", message: telegram.Message{Location: kyiv, Venue: &telegram.Venue{Location: kyiv, Title: "Office"}}, want: VenueUpdate},
+		{name: "contact", message: telegram.Message{Contact: &telegram.Contact{PhoneNumber: "+380000000000"}}, want: ContactUpdate},
+		{name: "photo", message: telegram.Message{Photo: []telegram.File{{FileID: "photo"}}, Caption: "Kyiv"}, want: MediaUpdate},
+		{name: "sticker", message: telegram.Message{Sticker: &telegram.File{FileID: "sticker"}}, want: MediaUpdate},
+		{name: "voice", message: telegram.Message{Voice: &telegram.File{FileID: "voice"}}, want: MediaUpdate},
+		{name: "new member", message: telegram.Message{NewChatMembers: []telegram.From{{ID: 1}}}, want: ServiceUpdate},
+		{name: "migration", message: telegram.Message{MigrateToChatID: -100456}, want: ServiceUpdate},
+		{name: "empty text", message: telegram.Message{Text: " "}, want: UnknownUpdate},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ClassifyMessage(tc.message))
+		})
+	}
+}
+
+func TestService_AddSubscription_kinds(t *testing.T) {
+	controller := gomock.NewController(t)
+	storage := mocks.NewMongoStorage(controller)
+	weather := mocks.NewWeatherService(controller)
+	tgService := NewService(storage, weather, mocks.NewTelegramService(controller), mocks.NewBotService(controller))
+	user := db.User{ID: primitive.ObjectID{1}, ChatID: 358383178}
+	private := telegram.Chat{ID: 358383178, Type: telegram.ChatPrivate}
+	kyiv := telegram.Location{Latitude: 50.45, Longitude: 30.52}
+
+	tests := []struct {
+		name       string
+		message    telegram.Message
+		state      db.SubscriptionStatus
+		want       string
+		setupMocks func()
+	}{
+		{
+			name:    "sticker while time is requested",
+			message: telegram.Message{Chat: private, Sticker: &telegram.File{FileID: "sticker"}},
+			state:   db.Subscribed,
+			want:    "Sorry, I can't read photos, stickers, voice messages or files.\nStep 1 of 2 ●○",
+		},
+		{
+			name:    "contact while location is requested",
+			message: telegram.Message{Chat: private, Contact: &telegram.Contact{PhoneNumber: "+380000000000"}},
+			state:   db.TimeUpdated,
+			want:    "Contacts can't be used to set up forecast.\nStep 2 of 2 ●●",
+		},
+		{
+			name:    "empty message of subscribed user",
+			message: telegram.Message{Chat: private},
+			state:   db.LocationProvided,
+			want:    "Sorry, I can't read this kind of message. Send a city",
+		},
+		{
+			name:    "venue while location is requested",
+			message: telegram.Message{Chat: private, Location: kyiv, Venue: &telegram.Venue{Location: kyiv, Title: "Office"}},
+			state:   db.TimeUpdated,
+			want:    "Location updated to Office",
+			setupMocks: func() {
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"subscriptionStatus", db.LocationProvided}, {"location", kyiv}, {"place", "Office"}}}}, user.ID).Return(nil)
+			},
+		},
+		{
+			name:    "venue of subscribed user",
+			message: telegram.Message{Chat: private, Location: kyiv, Venue: &telegram.Venue{Location: kyiv, Title: "Office"}},
+			state:   db.LocationProvided,
+			want:    "Location updated to Office",
+			setupMocks: func() {
+				weather.EXPECT().WeatherRequest(gomock.Any(), gomock.Any()).Return(nil, nil)
+				storage.EXPECT().Update(bson.D{{"$set", bson.D{{"location", kyiv}, {"city", ""}, {"place", "Office"}}}}, user.ID).Return(nil)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			storage.EXPECT().GetUser(user.ChatID).Return(user, nil)
+			storage.EXPECT().Touch(user.ID, gomock.Any()).Return(nil)
+			storage.EXPECT().UserSubscriptionStatus(user.ID).Return(int(tc.state), nil)
+			if tc.setupMocks != nil {
+				tc.setupMocks()
+			}
+
+			got, err := tgService.AddSubscription(&telegram.Update{Message: tc.message}, private.ID)
+			require.NoError(t, err)
+			assert.Contains(t, got.Get("text"), tc.want)
+		})
+	}
+
+	t.Run("photo in group is ignored", func(t *testing.T) {
+		group := telegram.Chat{ID: -100123, Type: telegram.ChatSupergroup}
+		got, err := tgService.AddSubscription(&telegram.Update{Message: telegram.Message{Chat: group, Photo: []telegram.File{{FileID: "photo"}}}}, group.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Get("text"))
+	})
+
+	t.Run("service message is ignored", func(t *testing.T) {
+		got, err := tgService.AddSubscription(&telegram.Update{Message: telegram.Message{Chat: private, PinnedMessage: &telegram.Message{Text: "Kyiv"}}}, private.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Get("text"))
+	})
+}
@@ -0,0 +1,173 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	tgapi "github.com/c1kzy/Telegram-API"
+	"github.com/phuslu/log"
+)
+
+// BotService for Bot API methods that are not covered by TelegramService
+type BotService interface {
+	SendPhoto(chatID int, photo []byte, caption string) error
+	SendPhotoURL(chatID int, photoURL, caption string) error
+	GetChatMember(chatID, userID int) (ChatMember, error)
+	AnswerCallbackQuery(callbackID, text string) error
+	EditMessageText(chatID, messageID int, text, replyMarkup string) error
+}
+
+// Client struct for Telegram Bot API methods
+type Client struct {
+	client *http.Client
+	url    string
+}
+
+var (
+	lock         = sync.Mutex{}
+	singleClient *Client
+)
+
+// GetClient is getting single instance for Bot API client
+func GetClient(cfg *tgapi.Config) *Client {
+	if singleClient == nil {
+		lock.Lock()
+		defer lock.Unlock()
+		if singleClient == nil {
+			singleClient = &Client{
+				client: http.DefaultClient,
+				url:    fmt.Sprintf("https://api.telegram.org/bot%v/%%s", cfg.Token),
+			}
+			log.Info().Msg("Bot API client created")
+		}
+	}
+	return singleClient
+}
+
+// SendPhoto sends PNG image with caption to a chat
+func (c *Client) SendPhoto(chatID int, photo []byte, caption string) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writer.WriteField("chat_id", strconv.Itoa(chatID)); err != nil {
+		return err
+	}
+	if err := writer.WriteField("caption", caption); err != nil {
+		return err
+	}
+	part, partErr := writer.CreateFormFile("photo", "forecast.png")
+	if partErr != nil {
+		return partErr
+	}
+	if _, err := part.Write(photo); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	response, err := c.client.Post(c.methodURL("sendPhoto"), writer.FormDataContentType(), &body)
+	if err != nil {
+		return fmt.Errorf("sending photo failed. ChatID:%v.Error:%w", chatID, err)
+	}
+
+	return checkResponse(response, chatID)
+}
+
+// SendPhotoURL sends image Telegram downloads from URL with caption to a chat
+func (c *Client) SendPhotoURL(chatID int, photoURL, caption string) error {
+	response, err := c.client.PostForm(c.methodURL("sendPhoto"), url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"photo":   {photoURL},
+		"caption": {caption},
+	})
+	if err != nil {
+		return fmt.Errorf("sending photo failed. ChatID:%v.Error:%w", chatID, err)
+	}
+
+	return checkResponse(response, chatID)
+}
+
+// GetChatMember returns member of a chat
+func (c *Client) GetChatMember(chatID, userID int) (ChatMember, error) {
+	var result struct {
+		OK          bool       `json:"ok"`
+		Result      ChatMember `json:"result"`
+		Description string     `json:"description"`
+	}
+
+	response, err := c.client.PostForm(c.methodURL("getChatMember"), url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"user_id": {strconv.Itoa(userID)},
+	})
+	if err != nil {
+		return ChatMember{}, fmt.Errorf("getting chat member failed. ChatID:%v.Error:%w", chatID, err)
+	}
+	defer response.Body.Close()
+
+	if decodeErr := json.NewDecoder(response.Body).Decode(&result); decodeErr != nil {
+		return ChatMember{}, fmt.Errorf("unable to decode chat member for ChatID:%v. Error:%w", chatID, decodeErr)
+	}
+	if !result.OK {
+		return ChatMember{}, fmt.Errorf("getting chat member failed. ChatID:%v. Description:%v", chatID, result.Description)
+	}
+
+	return result.Result, nil
+}
+
+// AnswerCallbackQuery stops loading indicator of pressed inline button. Text is shown as a notification if not empty
+func (c *Client) AnswerCallbackQuery(callbackID, text string) error {
+	response, err := c.client.PostForm(c.methodURL("answerCallbackQuery"), url.Values{
+		"callback_query_id": {callbackID},
+		"text":              {text},
+	})
+	if err != nil {
+		return fmt.Errorf("answering callback query failed. CallbackID:%v.Error:%w", callbackID, err)
+	}
+
+	return checkResponse(response, 0)
+}
+
+// EditMessageText replaces text and inline keyboard of a message sent by bot
+func (c *Client) EditMessageText(chatID, messageID int, text, replyMarkup string) error {
+	values := url.Values{
+		"chat_id":    {strconv.Itoa(chatID)},
+		"message_id": {strconv.Itoa(messageID)},
+		"text":       {text},
+	}
+	if replyMarkup != "" {
+		values.Set("reply_markup", replyMarkup)
+	}
+
+	response, err := c.client.PostForm(c.methodURL("editMessageText"), values)
+	if err != nil {
+		return fmt.Errorf("editing message failed. ChatID:%v.Error:%w", chatID, err)
+	}
+
+	return checkResponse(response, chatID)
+}
+
+func (c *Client) methodURL(method string) string {
+	return fmt.Sprintf(c.url, method)
+}
+
+func checkResponse(response *http.Response, chatID int) error {
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		responseBody, readErr := io.ReadAll(response.Body)
+		if readErr != nil {
+			return fmt.Errorf("%v response for ChatID:%v. Error:%w", response.StatusCode, chatID, readErr)
+		}
+		return fmt.Errorf("%v response for ChatID:%v. Response body:%s", response.StatusCode, chatID, responseBody)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	tgapi "github.com/c1kzy/Telegram-API"
+	"github.com/phuslu/log"
+)
+
+// HandlerFunc handles update and returns a reply for the chat
+type HandlerFunc func(update *Update, chatID int) (url.Values, error)
+
+// Dispatcher routes webhook updates to commands, user input and chat member handlers
+type Dispatcher struct {
+	sender   tgapi.TelegramService
+	commands map[string]HandlerFunc
+	input    HandlerFunc
+	member   HandlerFunc
+	callback HandlerFunc
+	live     HandlerFunc
+}
+
+// NewDispatcher creates dispatcher that sends replies with sender
+func NewDispatcher(sender tgapi.TelegramService) *Dispatcher {
+	return &Dispatcher{
+		sender:   sender,
+		commands: make(map[string]HandlerFunc),
+	}
+}
+
+// RegisterCommand registers handler for a command
+func (d *Dispatcher) RegisterCommand(command string, callback HandlerFunc) {
+	d.commands[command] = callback
+}
+
+// RegisterInput registers handler for messages that are not registered commands
+func (d *Dispatcher) RegisterInput(callback HandlerFunc) {
+	d.input = callback
+}
+
+// RegisterMemberUpdate registers handler for changes of bot's membership in chats
+func (d *Dispatcher) RegisterMemberUpdate(callback HandlerFunc) {
+	d.member = callback
+}
+
+// RegisterCallback registers handler for presses of inline keyboard buttons
+func (d *Dispatcher) RegisterCallback(callback HandlerFunc) {
+	d.callback = callback
+}
+
+// RegisterLiveLocation registers handler for updates of live location shared in chats
+func (d *Dispatcher) RegisterLiveLocation(callback HandlerFunc) {
+	d.live = callback
+}
+
+// TelegramHandler handles telegram webhook request
+func (d *Dispatcher) TelegramHandler(_ http.ResponseWriter, r *http.Request) {
+	var update Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		log.Error().Err(err).Msg("error occurred decoding update body")
+		return
+	}
+
+	handler, chatID := d.route(&update)
+	if handler == nil {
+		log.Debug().Msgf("No handler for update %v", update.UpdateID)
+		return
+	}
+
+	reply, replyErr := handler(&update, chatID)
+	if replyErr != nil {
+		log.Error().Err(replyErr).Msgf("an error occurred while attempting to retrieve an answer for ChatID:%v", chatID)
+		if reply.Get("text") == "" {
+			reply = url.Values{"chat_id": {strconv.Itoa(chatID)}, "text": {fmt.Sprintf("unable to find a response for %s", update.Message.Text)}}
+		}
+	}
+	if reply.Get("text") == "" {
+		return
+	}
+
+	if err := d.sender.SendResponse(chatID, reply); err != nil {
+		log.Error().Err(err).Msgf("SendResponse error for ChatID:%v", chatID)
+	}
+}
+
+// route returns handler for the update and chat it belongs to. Channel posts are handled as messages
+func (d *Dispatcher) route(update *Update) (HandlerFunc, int) {
+	if update.MyChatMember != nil {
+		return d.member, update.MyChatMember.Chat.ID
+	}
+	if update.CallbackQuery != nil {
+		//Buttons of inline messages have no message and chat to reply to
+		if update.CallbackQuery.Message == nil {
+			return nil, 0
+		}
+		return d.callback, update.CallbackQuery.Message.Chat.ID
+	}
+	//Live location is shared by editing the message with location, other edits are ignored
+	if update.EditedMessage != nil {
+		location := update.EditedMessage.Location
+		if location.Latitude == 0 && location.Longitude == 0 {
+			return nil, 0
+		}
+		return d.live, update.EditedMessage.Chat.ID
+	}
+	if update.ChannelPost != nil {
+		update.Message = *update.ChannelPost
+	}
+	//Edited messages, polls and other updates without a message have no chat to reply to
+	if update.Message.Chat.ID == 0 {
+		return nil, 0
+	}
+
+	command, _ := CommandName(update.Message.Text)
+	if handler, found := d.commands[command]; found {
+		return handler, update.Message.Chat.ID
+	}
+
+	return d.input, update.Message.Chat.ID
+}
+
+// CommandName splits text into command and its arguments. Bot mention used in group chats is removed from command
+func CommandName(text string) (string, string) {
+	name, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	if strings.HasPrefix(name, "/") {
+		name, _, _ = strings.Cut(name, "@")
+	}
+
+	return name, strings.TrimSpace(args)
+}
@@ -0,0 +1,162 @@
+package telegram
+
+// chat types
+const (
+	ChatPrivate    = "private"
+	ChatGroup      = "group"
+	ChatSupergroup = "supergroup"
+	ChatChannel    = "channel"
+)
+
+// chat member statuses
+const (
+	MemberCreator       = "creator"
+	MemberAdministrator = "administrator"
+	MemberMember        = "member"
+	MemberRestricted    = "restricted"
+	MemberLeft          = "left"
+	MemberKicked        = "kicked"
+)
+
+// Update struct for telegram webhook update
+type Update struct {
+	UpdateID      int                `json:"update_id"`
+	Message       Message            `json:"message"`
+	EditedMessage *Message           `json:"edited_message,omitempty"`
+	ChannelPost   *Message           `json:"channel_post,omitempty"`
+	MyChatMember  *ChatMemberUpdated `json:"my_chat_member,omitempty"`
+	CallbackQuery *CallbackQuery     `json:"callback_query,omitempty"`
+}
+
+// Message struct for telegram message
+type Message struct {
+	MessageID       int      `json:"message_id"`
+	Date            int      `json:"date"`
+	EditDate        int      `json:"edit_date,omitempty"`
+	Text            string   `json:"text"`
+	Caption         string   `json:"caption,omitempty"`
+	Chat            Chat     `json:"chat"`
+	From            From     `json:"from"`
+	SenderChat      *Chat    `json:"sender_chat,omitempty"`
+	ReplyToMessage  *Message `json:"reply_to_message,omitempty"`
+	Location        Location `json:"location"`
+	Venue           *Venue   `json:"venue,omitempty"`
+	Contact         *Contact `json:"contact,omitempty"`
+	MigrateToChatID int      `json:"migrate_to_chat_id"`
+
+	// media
+	Photo     []File `json:"photo,omitempty"`
+	Sticker   *File  `json:"sticker,omitempty"`
+	Animation *File  `json:"animation,omitempty"`
+	Audio     *File  `json:"audio,omitempty"`
+	Document  *File  `json:"document,omitempty"`
+	Video     *File  `json:"video,omitempty"`
+	VideoNote *File  `json:"video_note,omitempty"`
+	Voice     *File  `json:"voice,omitempty"`
+
+	// service messages
+	NewChatMembers   []From   `json:"new_chat_members,omitempty"`
+	LeftChatMember   *From    `json:"left_chat_member,omitempty"`
+	NewChatTitle     string   `json:"new_chat_title,omitempty"`
+	PinnedMessage    *Message `json:"pinned_message,omitempty"`
+	GroupChatCreated bool     `json:"group_chat_created,omitempty"`
+	MigrateFromID    int      `json:"migrate_from_chat_id,omitempty"`
+}
+
+// From struct for message sender
+type From struct {
+	ID           int    `json:"id"`
+	IsBot        bool   `json:"is_bot"`
+	FirstName    string `json:"first_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// Chat struct for chat the message belongs to
+type Chat struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+}
+
+// LivePeriodForever is live period of location shared without end
+const LivePeriodForever = 0x7FFFFFFF
+
+// Location struct for telegram body. LivePeriod is set while location is shared live
+type Location struct {
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	LivePeriod int     `json:"live_period,omitempty" bson:"-"`
+}
+
+// Venue struct for a place shared from the map
+type Venue struct {
+	Location Location `json:"location"`
+	Title    string   `json:"title"`
+	Address  string   `json:"address"`
+}
+
+// Contact struct for a shared phone contact
+type Contact struct {
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	UserID      int    `json:"user_id,omitempty"`
+}
+
+// File struct for photo, sticker, voice and other media of a message
+type File struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+}
+
+// CallbackQuery struct for a press of inline keyboard button
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    From     `json:"from"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data"`
+}
+
+// ChatMemberUpdated struct for changes of bot's membership in a chat
+type ChatMemberUpdated struct {
+	Chat          Chat       `json:"chat"`
+	From          From       `json:"from"`
+	Date          int        `json:"date"`
+	OldChatMember ChatMember `json:"old_chat_member"`
+	NewChatMember ChatMember `json:"new_chat_member"`
+}
+
+// ChatMember struct for member of a chat
+type ChatMember struct {
+	Status string `json:"status"`
+	User   From   `json:"user"`
+}
+
+// IsGroup reports if chat is a group, supergroup or channel
+func (c Chat) IsGroup() bool {
+	return c.Type == ChatGroup || c.Type == ChatSupergroup || c.Type == ChatChannel
+}
+
+// IsAdmin reports if member can configure the chat
+func (m ChatMember) IsAdmin() bool {
+	return m.Status == MemberCreator || m.Status == MemberAdministrator
+}
+
+// IsPresent reports if member is in the chat
+func (m ChatMember) IsPresent() bool {
+	return m.Status != MemberLeft && m.Status != MemberKicked
+}
+
+// HasMedia reports if message carries a photo, sticker, voice or other file
+func (m Message) HasMedia() bool {
+	return len(m.Photo) > 0 || m.Sticker != nil || m.Animation != nil || m.Audio != nil ||
+		m.Document != nil || m.Video != nil || m.VideoNote != nil || m.Voice != nil
+}
+
+// IsService reports if message is a notification about chat changes rather than a user message
+func (m Message) IsService() bool {
+	return m.MigrateToChatID != 0 || m.MigrateFromID != 0 || len(m.NewChatMembers) > 0 || m.LeftChatMember != nil ||
+		m.NewChatTitle != "" || m.PinnedMessage != nil || m.GroupChatCreated
+}
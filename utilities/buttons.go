@@ -12,6 +12,14 @@ var LocationButton = ReplyKeyboardMarkup{
 	},
 }
 
+// OnboardingLocationButtons sends location button with buttons to go back to time step or cancel subscription
+var OnboardingLocationButtons = ReplyKeyboardMarkup{
+	Keyboard: [][]KeyboardButton{
+		{KeyboardButton{Text: "Share my location", Location: true, OneTimeKeyboard: true, ResizeKeyboard: true}},
+		{KeyboardButton{Text: Back}, KeyboardButton{Text: Cancel}},
+	},
+}
+
 // MenuButtons sends a menu for unsubscribed user
 var MenuButtons = ReplyKeyboardMarkup{
 	Keyboard: [][]KeyboardButton{
@@ -0,0 +1,59 @@
+package utilities
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// InlineKeyboardButton struct for a button attached to a message
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// InlineKeyboardMarkup struct for inline keyboard layout
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineMarshal wraps an inline keyboard into JSON
+func InlineMarshal(keyboard InlineKeyboardMarkup) ([]byte, error) {
+	data, jsonErr := json.Marshal(keyboard)
+	if jsonErr != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %w", jsonErr)
+	}
+	return data, nil
+}
+
+// CallbackData joins callback action and its value
+func CallbackData(action, value string) string {
+	return fmt.Sprintf("%v:%v", action, value)
+}
+
+// ParseCallbackData splits callback data into action and value
+func ParseCallbackData(data string) (string, string) {
+	action, value, _ := strings.Cut(data, ":")
+	return action, value
+}
+
+// ChoiceMenu is a single row menu with the current option marked
+func ChoiceMenu(action string, options []string, current string) InlineKeyboardMarkup {
+	row := make([]InlineKeyboardButton, 0, len(options))
+	for _, option := range options {
+		text := option
+		if option == current {
+			text = "✅ " + option
+		}
+		row = append(row, InlineKeyboardButton{Text: text, CallbackData: CallbackData(action, option)})
+	}
+
+	return InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{row}}
+}
+
+// WithBackButton adds a row with a button that opens another menu
+func WithBackButton(keyboard InlineKeyboardMarkup, text, data string) InlineKeyboardMarkup {
+	rows := append([][]InlineKeyboardButton{}, keyboard.InlineKeyboard...)
+	rows = append(rows, []InlineKeyboardButton{{Text: text, CallbackData: data}})
+	return InlineKeyboardMarkup{InlineKeyboard: rows}
+}
@@ -1,8 +1,8 @@
 package utilities
 
-import api "github.com/c1kzy/Telegram-API"
+import "subscriptionbot/telegram"
 
 // IsLocationEmpty checks if location values received
-func IsLocationEmpty(loc api.Location) bool {
+func IsLocationEmpty(loc telegram.Location) bool {
 	return loc.Latitude == 0.0 && loc.Longitude == 0.0
 }
@@ -5,8 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
-
-	api "github.com/c1kzy/Telegram-API"
+	"subscriptionbot/telegram"
 )
 
 // KeyboardButton struct for a telegram button
@@ -23,7 +22,7 @@ type ReplyKeyboardMarkup struct {
 }
 
 // StartResponse for /start command
-func StartResponse(body *api.WebHookReqBody, chatID int) (url.Values, error) {
+func StartResponse(body *telegram.Update, chatID int) (url.Values, error) {
 	replyMarkup := ReplyKeyboardMarkup{
 		Keyboard: [][]KeyboardButton{
 			{KeyboardButton{Text: Subscribe}},
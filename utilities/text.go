@@ -2,12 +2,63 @@ package utilities
 
 // constants for different options
 const (
-	Start             = `Hello! This is weather forecast bot. Please hit subscribe button if you want weather forecast every day or unsubscribe if you were subscribed`
-	Subscribe         = "Subscribe"
-	Unsubscribe       = "Unsubscribe"
-	SubscribedOptions = `You can update the time you will be receiving weather at or the city you want to get the weather for:
-Enter city or share location to update weather forecast.Example: /city New York
-Enter time to update the time. Example: /time 07:30
-Unsubscribe option is also available below
+	Start              = `Hello! This is weather forecast bot. Please hit subscribe button if you want weather forecast every day or unsubscribe if you were subscribed`
+	GroupWelcome       = `Hello! This is weather forecast bot. Chat admins can subscribe this chat to daily weather forecast with /start. Reply to my messages to provide time and city`
+	AdminsOnly         = "Only chat admins can configure weather forecast for this chat"
+	Subscribe          = "Subscribe"
+	Unsubscribe        = "Unsubscribe"
+	Back               = "« Back"
+	Cancel             = "Cancel"
+	Skip               = "Skip"
+	TimeCommand        = "/time"
+	CityCommand        = "/city"
+	StopCommand        = "/stop"
+	HelpCommand        = "/help"
+	NowCommand         = "/now"
+	SwingCommand       = "/swing"
+	GoldenHourCommand  = "/goldenhour"
+	FormatCommand      = "/format"
+	PlacesCommand      = "/places"
+	UnitsCommand       = "/units"
+	SettingsCommand    = "/settings"
+	LocationCommand    = "/location"
+	TripCommand        = "/trip"
+	CommuteCommand     = "/commute"
+	UnitsMetric        = "metric"
+	UnitsImperial      = "imperial"
+	UnitsCallback      = "units"
+	FormatCallback     = "format"
+	TimeCallback       = "time"
+	SettingsCallback   = "settings"
+	SwingCallback      = "swing"
+	UndoCallback       = "undo"
+	OnboardingCallback = "onboarding"
+	GoldenCallback     = "goldenhour"
+	HourCallback       = "hour"
+	MinuteCallback     = "minute"
+	LocationCallback   = "location"
+	CityCallback       = "city"
+	LiveFollow         = "follow"
+	LiveLock           = "lock"
+	FormatText         = "text"
+	FormatChart        = "chart"
+	Off                = "off"
+	InvalidTime        = "invalid time, try again.Example: 07:30, 7:30 pm or half past seven"
+	SubscribedOptions  = `You can update the time you will be receiving weather at or the city you want to get the weather for:
+Set the city for weather forecast, or share location. Example: /city New York
+Live location moves forecast location while you share it. Keep current location with /location lock, follow it again with /location follow
+Set the time of daily forecast, UTC. Example: /time 07:30, /time 7pm or /time to pick it
+Get forecast right now for your city or any other. Example: /now or /now Paris
+Get an alert when temperature changes a lot since yesterday. Example: /swing 5 or /swing off
+Get a reminder before golden hour. Example: /goldenhour 30 or /goldenhour off
+Get forecast as a temperature chart or as text. Example: /format chart or /format text
+Choose metric or imperial units: /units
+Save places to get forecast for them too. Example: /places add Office New York, /places remove Office, /places list
+Choose places included in forecast. Example: /places include Office or /places exclude Office
+Get conditions for your commute in daily forecast. Example: /commute Kyiv 08:00 Brovary 18:00 or /commute off
+Get forecast for trip destination on trip dates. Example: /trip Paris 2024-05-01 2024-05-05, /trip cancel Paris or /trip to list trips
+See and edit all your settings: /settings
+Show this help: /help
+Unsubscribe with /stop or the button below
 `
 )
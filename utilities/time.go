@@ -1,19 +1,164 @@
 package utilities
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// ConvertTime returns a string with time based on layout
+// ErrInvalidTime is returned when input can't be interpreted as time of day
+var ErrInvalidTime = errors.New("invalid time")
+
+// numericTime matches "7", "7am", "7:30 pm", "19.30", "19h30", "0730"
+var numericTime = regexp.MustCompile(`^(\d{1,2}?)(?:[:.h]?(\d{2}))?\s*(am|pm|night)?$`)
+
+// hourWords for times written in words
+var hourWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+// namedTimes are phrases with fixed time in English, Ukrainian and German
+var namedTimes = map[string]string{
+	"noon":        "12:00",
+	"midday":      "12:00",
+	"midnight":    "00:00",
+	"полудень":    "12:00",
+	"опівдні":     "12:00",
+	"північ":      "00:00",
+	"опівночі":    "00:00",
+	"mittag":      "12:00",
+	"mitternacht": "00:00",
+}
+
+// dayParts turn 12h time in words into 24h time. Night is late evening or early morning depending on the hour
+var dayParts = map[string]string{
+	"in the morning":   "am",
+	"in the afternoon": "pm",
+	"in the evening":   "pm",
+	"at night":         "night",
+	"ранку":            "am",
+	"дня":              "pm",
+	"вечора":           "pm",
+	"ночі":             "night",
+	"morgens":          "am",
+	"nachmittags":      "pm",
+	"abends":           "pm",
+	"nachts":           "night",
+}
+
+// ConvertTime returns a string with time based on layout. Accepts 24h and 12h formats like "19:30", "19.30", "0730",
+// "7:30 pm", "7am" and phrases like "noon", "seven o'clock", "half past seven", "7 ранку" or "7 Uhr abends"
 func ConvertTime(inputTime string) (string, error) {
-	layout := "15:04"
-	parsedTime, err := time.Parse(layout, inputTime)
-	if err != nil {
-		fmt.Println("Error parsing time:", err)
-		return "", err
+	text := normalizeTime(inputTime)
+	if named, found := namedTimes[text]; found {
+		return named, nil
 	}
 
-	return parsedTime.Format(layout), nil
+	hour, minute, meridiem, parsed := parseNumericTime(text)
+	if !parsed {
+		hour, minute, meridiem, parsed = parseWordTime(text)
+	}
+	if !parsed {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTime, inputTime)
+	}
+
+	hour, valid := toDayHour(hour, meridiem)
+	if !valid || minute > 59 {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTime, inputTime)
+	}
 
+	return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC).Format("15:04"), nil
+}
+
+// normalizeTime lowercases input and replaces spellings of am/pm and day parts
+func normalizeTime(input string) string {
+	text := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	text = strings.NewReplacer("a.m.", "am", "p.m.", "pm", "a.m", "am", "p.m", "pm", " uhr", "").Replace(text)
+	for part, meridiem := range dayParts {
+		if strings.HasSuffix(text, " "+part) {
+			text = strings.TrimSuffix(text, " "+part) + " " + meridiem
+		}
+	}
+	return text
+}
+
+func parseNumericTime(text string) (int, int, string, bool) {
+	match := numericTime.FindStringSubmatch(text)
+	if match == nil || match[1] == "" {
+		return 0, 0, "", false
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	return hour, minute, match[3], true
+}
+
+// parseWordTime parses "seven", "seven o'clock", "half past seven", "quarter past seven", "quarter to eight" with optional am/pm
+func parseWordTime(text string) (int, int, string, bool) {
+	meridiem := ""
+	for _, suffix := range []string{" am", " pm", " night"} {
+		if strings.HasSuffix(text, suffix) {
+			meridiem = strings.TrimSpace(suffix)
+			text = strings.TrimSuffix(text, suffix)
+		}
+	}
+	text = strings.TrimSuffix(text, " o'clock")
+
+	minute, hourWord := 0, text
+	switch {
+	case strings.HasPrefix(text, "half past "):
+		minute, hourWord = 30, strings.TrimPrefix(text, "half past ")
+	case strings.HasPrefix(text, "quarter past "):
+		minute, hourWord = 15, strings.TrimPrefix(text, "quarter past ")
+	case strings.HasPrefix(text, "quarter to "):
+		minute, hourWord = 45, strings.TrimPrefix(text, "quarter to ")
+	}
+
+	hour, found := hourWords[hourWord]
+	if !found {
+		return 0, 0, "", false
+	}
+	if minute == 45 {
+		hour--
+		if hour == 0 {
+			hour = 12
+		}
+	}
+	return hour, minute, meridiem, true
+}
+
+// toDayHour converts hour with am/pm or night to 24h hour
+func toDayHour(hour int, meridiem string) (int, bool) {
+	switch meridiem {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		return hour % 12, true
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		return hour%12 + 12, true
+	case "night":
+		switch {
+		case hour < 1 || hour > 12:
+			return 0, false
+		case hour == 12:
+			return 0, true
+		case hour < 6:
+			return hour, true
+		default:
+			return hour + 12, true
+		}
+	default:
+		return hour, hour <= 23
+	}
 }
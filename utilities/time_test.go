@@ -0,0 +1,70 @@
+package utilities_test
+
+import (
+	"subscriptionbot/utilities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "24h", input: "15:04", want: "15:04"},
+		{name: "single digit hour", input: "7:30", want: "07:30"},
+		{name: "dot separator", input: "19.30", want: "19:30"},
+		{name: "h separator", input: "19h30", want: "19:30"},
+		{name: "no separator", input: "0730", want: "07:30"},
+		{name: "three digits", input: "730", want: "07:30"},
+		{name: "hour only", input: "19", want: "19:00"},
+		{name: "am", input: "7am", want: "07:00"},
+		{name: "pm with minutes", input: "7:30 pm", want: "19:30"},
+		{name: "dotted pm", input: "7:30 P.M.", want: "19:30"},
+		{name: "midnight am", input: "12am", want: "00:00"},
+		{name: "noon pm", input: "12 pm", want: "12:00"},
+		{name: "noon", input: "Noon", want: "12:00"},
+		{name: "midnight", input: "midnight", want: "00:00"},
+		{name: "word", input: "seven", want: "07:00"},
+		{name: "o'clock", input: "seven o'clock", want: "07:00"},
+		{name: "half past", input: "half past seven", want: "07:30"},
+		{name: "half past pm", input: "half past seven pm", want: "19:30"},
+		{name: "quarter past", input: "quarter past nine", want: "09:15"},
+		{name: "quarter to", input: "quarter to eight", want: "07:45"},
+		{name: "quarter to one", input: "quarter to one pm", want: "12:45"},
+		{name: "in the evening", input: "seven in the evening", want: "19:00"},
+		{name: "in the morning", input: "6:45 in the morning", want: "06:45"},
+		{name: "twelve at night", input: "twelve at night", want: "00:00"},
+		{name: "12 at night", input: "12 at night", want: "00:00"},
+		{name: "late night", input: "eleven at night", want: "23:00"},
+		{name: "early night", input: "2 at night", want: "02:00"},
+		{name: "ukrainian morning", input: "7 ранку", want: "07:00"},
+		{name: "ukrainian evening", input: "8:30 вечора", want: "20:30"},
+		{name: "ukrainian night", input: "12 ночі", want: "00:00"},
+		{name: "ukrainian noon", input: "Опівдні", want: "12:00"},
+		{name: "german hour", input: "19 Uhr", want: "19:00"},
+		{name: "german evening", input: "7 Uhr abends", want: "19:00"},
+		{name: "german midnight", input: "Mitternacht", want: "00:00"},
+		{name: "extra spaces", input: "  7:30   pm ", want: "19:30"},
+		{name: "hour out of range", input: "25:00", wantErr: true},
+		{name: "minute out of range", input: "7:75", wantErr: true},
+		{name: "13pm", input: "13pm", wantErr: true},
+		{name: "0am", input: "0am", wantErr: true},
+		{name: "city", input: "New York", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := utilities.ConvertTime(tc.input)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, utilities.ErrInvalidTime)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
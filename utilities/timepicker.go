@@ -0,0 +1,40 @@
+package utilities
+
+import "fmt"
+
+// MinuteStep of minute grid in time picker
+const MinuteStep = 5
+
+// HourPicker is an inline grid of 24 hours
+func HourPicker() InlineKeyboardMarkup {
+	var keyboard [][]InlineKeyboardButton
+	for row := 0; row < 4; row++ {
+		var buttons []InlineKeyboardButton
+		for column := 0; column < 6; column++ {
+			hour := fmt.Sprintf("%02d", row*6+column)
+			buttons = append(buttons, InlineKeyboardButton{Text: hour, CallbackData: CallbackData(HourCallback, hour)})
+		}
+		keyboard = append(keyboard, buttons)
+	}
+
+	return InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// MinutePicker is an inline grid of minutes of the hour with a button back to hours
+func MinutePicker(hour string) InlineKeyboardMarkup {
+	var keyboard [][]InlineKeyboardButton
+	var buttons []InlineKeyboardButton
+	for minute := 0; minute < 60; minute += MinuteStep {
+		value := fmt.Sprintf("%v:%02d", hour, minute)
+		buttons = append(buttons, InlineKeyboardButton{Text: value, CallbackData: CallbackData(MinuteCallback, value)})
+		if len(buttons) == 4 {
+			keyboard = append(keyboard, buttons)
+			buttons = nil
+		}
+	}
+	if len(buttons) > 0 {
+		keyboard = append(keyboard, buttons)
+	}
+
+	return WithBackButton(InlineKeyboardMarkup{InlineKeyboard: keyboard}, "« Hours", CallbackData(TimeCallback, ""))
+}
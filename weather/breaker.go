@@ -0,0 +1,255 @@
+package weatherAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"subscriptionbot/db"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+// ErrProviderUnavailable is returned while breaker is open and there is no cached response
+var ErrProviderUnavailable = errors.New("weather provider is unavailable")
+
+// BreakerState for circuit breaker states
+type BreakerState string
+
+// circuit breaker states
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// Breaker wraps WeatherService, stops calling provider after consecutive failures and serves cached responses while open
+type Breaker struct {
+	service   WeatherService
+	threshold int
+	cooldown  time.Duration
+
+	mu            sync.Mutex
+	failures      int
+	openedAt      time.Time
+	probing       bool
+	lastErr       error
+	weatherCache  map[string]WeatherData
+	forecastCache map[string]ForecastData
+}
+
+// Health struct for provider health report
+type Health struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	OpenedAt            *time.Time   `json:"openedAt,omitempty"`
+	LastError           string       `json:"lastError,omitempty"`
+}
+
+// NewBreaker creates a circuit breaker around weather service
+func NewBreaker(service WeatherService, cfg BreakerConfig) *Breaker {
+	return &Breaker{
+		service:       service,
+		threshold:     cfg.Threshold,
+		cooldown:      cfg.Cooldown,
+		weatherCache:  make(map[string]WeatherData),
+		forecastCache: make(map[string]ForecastData),
+	}
+}
+
+// WeatherRequest returns current weather as a forecast message
+func (b *Breaker) WeatherRequest(ctx context.Context, user db.User) (url.Values, error) {
+	weather, weatherErr := b.CurrentWeather(ctx, user)
+	if weatherErr != nil {
+		return url.Values{}, weatherErr
+	}
+
+	return url.Values{
+		"chat_id": {strconv.Itoa(user.ChatID)},
+		"text":    {FormatForecast(weather)},
+	}, nil
+}
+
+// CurrentWeather returns current weather or the last cached one marked as stale while provider is unavailable
+func (b *Breaker) CurrentWeather(ctx context.Context, user db.User) (WeatherData, error) {
+	key := cacheKey(user)
+	if !b.allow() {
+		return b.cachedWeather(key)
+	}
+
+	weather, weatherErr := b.service.CurrentWeather(ctx, user)
+	if weatherErr != nil {
+		if b.failure(weatherErr) {
+			return b.cachedWeather(key)
+		}
+		return WeatherData{}, weatherErr
+	}
+	b.success()
+
+	b.mu.Lock()
+	b.weatherCache[key] = weather
+	b.mu.Unlock()
+
+	return weather, nil
+}
+
+// Forecast returns forecast or the last cached one marked as stale while provider is unavailable
+func (b *Breaker) Forecast(ctx context.Context, user db.User) (ForecastData, error) {
+	key := cacheKey(user)
+	if !b.allow() {
+		return b.cachedForecast(key)
+	}
+
+	forecast, forecastErr := b.service.Forecast(ctx, user)
+	if forecastErr != nil {
+		if b.failure(forecastErr) {
+			return b.cachedForecast(key)
+		}
+		return ForecastData{}, forecastErr
+	}
+	b.success()
+
+	b.mu.Lock()
+	b.forecastCache[key] = forecast
+	b.mu.Unlock()
+
+	return forecast, nil
+}
+
+// Health returns current state of the breaker
+func (b *Breaker) Health() Health {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	health := Health{
+		State:               b.state(time.Now()),
+		ConsecutiveFailures: b.failures,
+	}
+	if health.State != BreakerClosed {
+		openedAt := b.openedAt
+		health.OpenedAt = &openedAt
+	}
+	if b.lastErr != nil {
+		health.LastError = b.lastErr.Error()
+	}
+
+	return health
+}
+
+// HealthHandler reports provider health. Responds with 503 while breaker is open
+func (b *Breaker) HealthHandler(w http.ResponseWriter, _ *http.Request) {
+	health := b.Health()
+
+	w.Header().Set("Content-Type", "application/json")
+	if health.State == BreakerOpen {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		log.Error().Err(err).Msg("unable to write weather provider health")
+	}
+}
+
+// state returns breaker state. Open breaker becomes half-open after cooldown to let a trial request through
+func (b *Breaker) state(now time.Time) BreakerState {
+	if b.failures < b.threshold {
+		return BreakerClosed
+	}
+	if now.Sub(b.openedAt) < b.cooldown {
+		return BreakerOpen
+	}
+	return BreakerHalfOpen
+}
+
+// allow reports if request can be sent to provider. Half-open breaker lets a single trial request through,
+// other requests are served from cache until the trial request finishes
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state(time.Now()) {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return false
+	}
+}
+
+// failure records provider failure and reports if it counts against provider health.
+// Errors caused by user input do not open the breaker
+func (b *Breaker) failure(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrNoLocation) {
+		return false
+	}
+
+	b.failures++
+	b.lastErr = err
+	if b.failures >= b.threshold {
+		if b.failures == b.threshold {
+			log.Warn().Msgf("Weather provider circuit breaker opened after %v failures. Error:%v", b.failures, err)
+		}
+		b.openedAt = time.Now()
+	}
+
+	return true
+}
+
+func (b *Breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures >= b.threshold {
+		log.Info().Msg("Weather provider circuit breaker closed")
+	}
+	b.probing = false
+	b.failures = 0
+	b.lastErr = nil
+}
+
+func (b *Breaker) cachedWeather(key string) (WeatherData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	weather, found := b.weatherCache[key]
+	if !found {
+		return WeatherData{}, ErrProviderUnavailable
+	}
+	weather.Stale = true
+
+	return weather, nil
+}
+
+func (b *Breaker) cachedForecast(key string) (ForecastData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	forecast, found := b.forecastCache[key]
+	if !found {
+		return ForecastData{}, ErrProviderUnavailable
+	}
+	forecast.Stale = true
+
+	return forecast, nil
+}
+
+// cacheKey returns a key of user's city or location and units for cached responses
+func cacheKey(user db.User) string {
+	if user.City != "" {
+		return fmt.Sprintf("%v|%v", user.City, user.Units)
+	}
+	return fmt.Sprintf("%.2f,%.2f|%v", user.Location.Latitude, user.Location.Longitude, user.Units)
+}
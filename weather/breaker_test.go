@@ -0,0 +1,100 @@
+package weatherAPI_test
+
+import (
+	"context"
+	"errors"
+	"subscriptionbot/db"
+	"subscriptionbot/mocks"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker_CurrentWeather(t *testing.T) {
+	controller := gomock.NewController(t)
+	service := mocks.NewWeatherService(controller)
+	breaker := weatherAPI.NewBreaker(service, weatherAPI.BreakerConfig{Threshold: 2, Cooldown: time.Hour})
+
+	user := db.User{City: "Kyiv"}
+	weather := weatherAPI.WeatherData{Name: "Kyiv", Weather: []weatherAPI.Weather{{Description: "clear sky"}}}
+	providerErr := errors.New("connection refused")
+
+	service.EXPECT().CurrentWeather(gomock.Any(), user).Return(weather, nil)
+	got, err := breaker.CurrentWeather(context.Background(), user)
+	require.NoError(t, err)
+	assert.False(t, got.Stale)
+
+	service.EXPECT().CurrentWeather(gomock.Any(), user).Return(weatherAPI.WeatherData{}, providerErr).Times(2)
+	for i := 0; i < 2; i++ {
+		got, err = breaker.CurrentWeather(context.Background(), user)
+		require.NoError(t, err)
+		assert.True(t, got.Stale)
+	}
+	assert.Equal(t, weatherAPI.BreakerOpen, breaker.Health().State)
+
+	//Provider is not called while breaker is open
+	got, err = breaker.CurrentWeather(context.Background(), user)
+	require.NoError(t, err)
+	assert.True(t, got.Stale)
+	assert.Equal(t, "Kyiv", got.Name)
+
+	_, err = breaker.CurrentWeather(context.Background(), db.User{City: "Lviv"})
+	assert.ErrorIs(t, err, weatherAPI.ErrProviderUnavailable)
+}
+
+func TestBreaker_UserErrors(t *testing.T) {
+	controller := gomock.NewController(t)
+	service := mocks.NewWeatherService(controller)
+	breaker := weatherAPI.NewBreaker(service, weatherAPI.BreakerConfig{Threshold: 1, Cooldown: time.Hour})
+
+	user := db.User{City: "Atlantis"}
+	service.EXPECT().CurrentWeather(gomock.Any(), user).Return(weatherAPI.WeatherData{}, weatherAPI.ErrNotFound)
+
+	_, err := breaker.CurrentWeather(context.Background(), user)
+	assert.ErrorIs(t, err, weatherAPI.ErrNotFound)
+	assert.Equal(t, weatherAPI.BreakerClosed, breaker.Health().State)
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	controller := gomock.NewController(t)
+	service := mocks.NewWeatherService(controller)
+	breaker := weatherAPI.NewBreaker(service, weatherAPI.BreakerConfig{Threshold: 1, Cooldown: 10 * time.Millisecond})
+
+	user := db.User{City: "Kyiv"}
+	weather := weatherAPI.WeatherData{Name: "Kyiv"}
+	service.EXPECT().CurrentWeather(gomock.Any(), user).Return(weather, nil)
+	service.EXPECT().CurrentWeather(gomock.Any(), user).Return(weatherAPI.WeatherData{}, errors.New("connection refused"))
+	_, err := breaker.CurrentWeather(context.Background(), user)
+	require.NoError(t, err)
+	_, err = breaker.CurrentWeather(context.Background(), user)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, weatherAPI.BreakerHalfOpen, breaker.Health().State)
+
+	//Only the trial request reaches provider, others get cached weather until it finishes
+	started, release := make(chan struct{}), make(chan struct{})
+	service.EXPECT().CurrentWeather(gomock.Any(), user).DoAndReturn(func(context.Context, db.User) (weatherAPI.WeatherData, error) {
+		close(started)
+		<-release
+		return weather, nil
+	}).Times(1)
+
+	trial := make(chan weatherAPI.WeatherData)
+	go func() {
+		got, _ := breaker.CurrentWeather(context.Background(), user)
+		trial <- got
+	}()
+	<-started
+
+	got, err := breaker.CurrentWeather(context.Background(), user)
+	require.NoError(t, err)
+	assert.True(t, got.Stale)
+
+	close(release)
+	assert.False(t, (<-trial).Stale)
+	assert.Equal(t, weatherAPI.BreakerClosed, breaker.Health().State)
+}
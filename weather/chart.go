@@ -0,0 +1,198 @@
+package weatherAPI
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// chart layout in pixels
+const (
+	chartWidth   = 800
+	chartHeight  = 400
+	chartPadding = 40
+	glyphScale   = 2
+)
+
+var (
+	chartBackground  = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	chartGrid        = color.RGBA{R: 220, G: 220, B: 220, A: 255}
+	chartZero        = color.RGBA{R: 150, G: 150, B: 150, A: 255}
+	chartTemperature = color.RGBA{R: 230, G: 80, B: 40, A: 255}
+	chartRain        = color.RGBA{R: 70, G: 130, B: 220, A: 255}
+	chartLabel       = color.RGBA{R: 90, G: 90, B: 90, A: 255}
+)
+
+// glyphs of a 3x5 pixel font for chart labels
+var glyphs = map[rune][5]string{
+	'0': {"111", "101", "101", "101", "111"},
+	'1': {"010", "110", "010", "010", "111"},
+	'2': {"111", "001", "111", "100", "111"},
+	'3': {"111", "001", "111", "001", "111"},
+	'4': {"101", "101", "111", "001", "001"},
+	'5': {"111", "100", "111", "001", "111"},
+	'6': {"111", "100", "111", "101", "111"},
+	'7': {"111", "001", "001", "001", "001"},
+	'8': {"111", "101", "111", "101", "111"},
+	'9': {"111", "101", "111", "001", "111"},
+	'-': {"000", "000", "111", "000", "000"},
+	'°': {"110", "110", "000", "000", "000"},
+	'h': {"100", "100", "111", "101", "101"},
+}
+
+// RenderChart draws temperature line and precipitation bars of the forecast as PNG image.
+// Hours in city's local time and min and max temperature are labeled on the axes
+func RenderChart(forecast ForecastData) ([]byte, error) {
+	if len(forecast.List) < 2 {
+		return nil, fmt.Errorf("not enough forecast data to render a chart")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	fillRect(img, img.Bounds(), chartBackground)
+
+	plot := image.Rect(chartPadding, chartPadding, chartWidth-chartPadding, chartHeight-chartPadding)
+	step := float64(plot.Dx()) / float64(len(forecast.List)-1)
+	x := func(i int) int {
+		return plot.Min.X + int(math.Round(float64(i)*step))
+	}
+
+	minTemp, maxTemp, maxPrecipitation := chartRange(forecast.List)
+	y := func(temp float64) int {
+		return plot.Max.Y - int(math.Round((temp-minTemp)/(maxTemp-minTemp)*float64(plot.Dy())))
+	}
+
+	//Vertical lines separate days in city's local time
+	for i, item := range forecast.List {
+		if item.Time(forecast.City.Timezone).Hour() < 3 {
+			drawLine(img, image.Pt(x(i), plot.Min.Y), image.Pt(x(i), plot.Max.Y), chartGrid, 1)
+		}
+	}
+	drawLine(img, image.Pt(plot.Min.X, plot.Max.Y), image.Pt(plot.Max.X, plot.Max.Y), chartGrid, 1)
+	if minTemp < 0 && maxTemp > 0 {
+		drawLine(img, image.Pt(plot.Min.X, y(0)), image.Pt(plot.Max.X, y(0)), chartZero, 1)
+	}
+
+	barWidth := int(step/2) + 1
+	for i, item := range forecast.List {
+		if item.Precipitation() <= 0 {
+			continue
+		}
+		height := int(math.Round(item.Precipitation() / maxPrecipitation * float64(plot.Dy()) / 3))
+		fillRect(img, image.Rect(x(i)-barWidth/2, plot.Max.Y-height, x(i)+barWidth/2+1, plot.Max.Y), chartRain)
+	}
+
+	for i := 1; i < len(forecast.List); i++ {
+		drawLine(img, image.Pt(x(i-1), y(forecast.List[i-1].Main.Temp)), image.Pt(x(i), y(forecast.List[i].Main.Temp)), chartTemperature, 3)
+	}
+
+	//Every other step is labeled, so labels of 3-hour steps don't overlap
+	for i := 0; i < len(forecast.List); i += 2 {
+		drawLine(img, image.Pt(x(i), plot.Max.Y), image.Pt(x(i), plot.Max.Y+4), chartLabel, 1)
+		label := fmt.Sprintf("%dh", forecast.List[i].Time(forecast.City.Timezone).Hour())
+		drawText(img, label, image.Pt(x(i)-textWidth(label)/2, plot.Max.Y+8), chartLabel)
+	}
+	lowest, highest := forecast.List[0].Main.Temp, forecast.List[0].Main.Temp
+	for _, item := range forecast.List {
+		lowest, highest = math.Min(lowest, item.Main.Temp), math.Max(highest, item.Main.Temp)
+	}
+	for _, temp := range []float64{lowest, highest} {
+		label := fmt.Sprintf("%d°", int(math.Round(temp)))
+		drawText(img, label, image.Pt(plot.Min.X-textWidth(label)-4, y(temp)-5*glyphScale/2), chartLabel)
+	}
+
+	var buf bytes.Buffer
+	if encodeErr := png.Encode(&buf, img); encodeErr != nil {
+		return nil, fmt.Errorf("unable to encode chart: %w", encodeErr)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// chartRange returns temperature bounds with a margin and max precipitation of forecast steps
+func chartRange(items []ForecastItem) (float64, float64, float64) {
+	minTemp, maxTemp := items[0].Main.Temp, items[0].Main.Temp
+	maxPrecipitation := 1.0
+	for _, item := range items {
+		minTemp = math.Min(minTemp, item.Main.Temp)
+		maxTemp = math.Max(maxTemp, item.Main.Temp)
+		maxPrecipitation = math.Max(maxPrecipitation, item.Precipitation())
+	}
+
+	return math.Floor(minTemp) - 1, math.Ceil(maxTemp) + 1, maxPrecipitation
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	rect = rect.Intersect(img.Bounds())
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			img.Set(px, py, c)
+		}
+	}
+}
+
+// drawLine draws a line of given thickness using Bresenham's algorithm
+func drawLine(img *image.RGBA, from, to image.Point, c color.Color, thickness int) {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+	err := dx + dy
+	for {
+		fillRect(img, image.Rect(from.X-thickness/2, from.Y-thickness/2, from.X+thickness/2+1, from.Y+thickness/2+1), c)
+		if from == to {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			from.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			from.Y += sy
+		}
+	}
+}
+
+// drawText draws text with pixel font from top left point. Runes without glyph are skipped
+func drawText(img *image.RGBA, text string, at image.Point, c color.Color) {
+	for _, r := range text {
+		glyph, found := glyphs[r]
+		if !found {
+			continue
+		}
+		for row, line := range glyph {
+			for col, pixel := range line {
+				if pixel == '1' {
+					px, py := at.X+col*glyphScale, at.Y+row*glyphScale
+					fillRect(img, image.Rect(px, py, px+glyphScale, py+glyphScale), c)
+				}
+			}
+		}
+		at.X += 4 * glyphScale
+	}
+}
+
+// textWidth returns width of text drawn with pixel font
+func textWidth(text string) int {
+	return len([]rune(text))*4*glyphScale - glyphScale
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
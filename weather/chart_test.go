@@ -0,0 +1,76 @@
+package weatherAPI_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderChart(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast weatherAPI.ForecastData
+		wantErr  bool
+	}{
+		{
+			name:     "empty forecast",
+			forecast: weatherAPI.ForecastData{},
+			wantErr:  true,
+		},
+		{
+			name:     "single step",
+			forecast: weatherAPI.ForecastData{List: []weatherAPI.ForecastItem{{Dt: 1700000000, Main: weatherAPI.Main{Temp: 5}}}},
+			wantErr:  true,
+		},
+		{
+			name: "same temperature",
+			forecast: weatherAPI.ForecastData{List: []weatherAPI.ForecastItem{
+				{Dt: 1700000000, Main: weatherAPI.Main{Temp: 5}},
+				{Dt: 1700010800, Main: weatherAPI.Main{Temp: 5}},
+			}},
+		},
+		{
+			name: "temperature around zero with rain and snow",
+			forecast: weatherAPI.ForecastData{City: weatherAPI.ForecastCity{Timezone: 7200}, List: []weatherAPI.ForecastItem{
+				{Dt: 1700000000, Main: weatherAPI.Main{Temp: -3.5}, Snow: weatherAPI.Precipitation{ThreeHours: 4}},
+				{Dt: 1700010800, Main: weatherAPI.Main{Temp: 0.4}},
+				{Dt: 1700021600, Main: weatherAPI.Main{Temp: 2.6}, Rain: weatherAPI.Precipitation{ThreeHours: 0.2}},
+				{Dt: 1700032400, Main: weatherAPI.Main{Temp: 1}, Rain: weatherAPI.Precipitation{ThreeHours: 25}},
+			}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chart, err := weatherAPI.RenderChart(tc.forecast)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, chart)
+				return
+			}
+			require.NoError(t, err)
+
+			img, decodeErr := png.Decode(bytes.NewReader(chart))
+			require.NoError(t, decodeErr)
+			bounds := img.Bounds()
+			assert.True(t, drawn(img, image.Rect(0, 0, 36, bounds.Max.Y)), "temperature labels")
+			assert.True(t, drawn(img, image.Rect(0, bounds.Max.Y-30, bounds.Max.X, bounds.Max.Y)), "hour labels")
+		})
+	}
+}
+
+// drawn reports if anything is drawn on white background in the area
+func drawn(img image.Image, area image.Rectangle) bool {
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+				return true
+			}
+		}
+	}
+	return false
+}
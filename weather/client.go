@@ -0,0 +1,103 @@
+package weatherAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Errors returned for OpenWeatherMap response status codes
+var (
+	ErrUnauthorized  = errors.New("weather API key is invalid")
+	ErrNotFound      = errors.New("location not found")
+	ErrRateLimited   = errors.New("weather API rate limit exceeded")
+	ErrEmptyResponse = errors.New("weather API response is empty")
+	ErrNoLocation    = errors.New("user has no city or location")
+)
+
+// HTTPClient for requests to weather provider
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// get sends GET request with API key from the pool and decodes JSON response into target.
+// Rate limited keys are exhausted and the request is retried with the next key
+func (w *WeatherAPI) get(ctx context.Context, requestURL string, target any) error {
+	var getErr error
+	for {
+		key, keyErr := w.keys.Acquire()
+		if keyErr != nil {
+			if getErr != nil {
+				return fmt.Errorf("%w: %w", keyErr, getErr)
+			}
+			return keyErr
+		}
+
+		getErr = w.getWithKey(ctx, requestURL, key, target)
+		if !errors.Is(getErr, ErrRateLimited) {
+			return getErr
+		}
+		w.keys.Exhaust(key)
+	}
+}
+
+// Keys returns pool of API keys used for requests
+func (w *WeatherAPI) Keys() *KeyPool {
+	return w.keys
+}
+
+func (w *WeatherAPI) getWithKey(ctx context.Context, requestURL, key string, target any) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if reqErr != nil {
+		return fmt.Errorf("unable to create weather API request: %w", reqErr)
+	}
+	query := req.URL.Query()
+	query.Set("appid", key)
+	req.URL.RawQuery = query.Encode()
+
+	resp, respErr := w.client.Do(req)
+	if respErr != nil {
+		return fmt.Errorf("weather API request failed: %w", respErr)
+	}
+	defer resp.Body.Close()
+
+	if statusErr := statusError(resp.StatusCode); statusErr != nil {
+		return statusErr
+	}
+
+	respBody, respBodyErr := io.ReadAll(resp.Body)
+	if respBodyErr != nil {
+		return fmt.Errorf("unable to read weather API response body: %w", respBodyErr)
+	}
+	if len(respBody) == 0 {
+		return ErrEmptyResponse
+	}
+
+	if marshalErr := json.Unmarshal(respBody, target); marshalErr != nil {
+		return fmt.Errorf("error unmarshalling JSON for weather API request: %w", marshalErr)
+	}
+
+	return nil
+}
+
+// statusError maps response status code to typed errors
+func statusError(statusCode int) error {
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return nil
+	case statusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return fmt.Errorf("weather API responded with status %v", statusCode)
+	}
+}
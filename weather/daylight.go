@@ -0,0 +1,35 @@
+package weatherAPI
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sunrise returns sunrise time converted with response's timezone offset
+func (w WeatherData) Sunrise() time.Time {
+	return time.Unix(int64(w.Sys.Sunrise), 0).In(w.zone())
+}
+
+// Sunset returns sunset time converted with response's timezone offset
+func (w WeatherData) Sunset() time.Time {
+	return time.Unix(int64(w.Sys.Sunset), 0).In(w.zone())
+}
+
+// DayLength returns duration between sunrise and sunset
+func (w WeatherData) DayLength() time.Duration {
+	return w.Sunset().Sub(w.Sunrise())
+}
+
+func (w WeatherData) zone() *time.Location {
+	return time.FixedZone("", w.Timezone)
+}
+
+// FormatDaylight renders sunrise, sunset and day length
+func FormatDaylight(weather WeatherData) string {
+	if weather.Sys.Sunrise == 0 || weather.Sys.Sunset == 0 {
+		return ""
+	}
+	dayLength := weather.DayLength()
+
+	return fmt.Sprintf("🌅Sunrise %v. 🌇Sunset %v\nDay length %vh %02vm", weather.Sunrise().Format("15:04"), weather.Sunset().Format("15:04"), int(dayLength.Hours()), int(dayLength.Minutes())%60)
+}
@@ -0,0 +1,94 @@
+package weatherAPI_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+)
+
+// API keys the fake OpenWeatherMap server rejects
+const (
+	invalidKey   = "invalid"
+	exhaustedKey = "exhausted"
+)
+
+// newFakeOpenWeather starts a server that serves recorded OpenWeatherMap responses from testdata.
+// Geo lookup knows only Kyiv, "Nowhere" responds with 404 and any other city with empty array.
+// Current weather for latitude 90 has no conditions, imperial units are served from a separate fixture
+func newFakeOpenWeather(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geo/1.0/direct", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("q") {
+		case "Kyiv":
+			serveFixture(t, w, http.StatusOK, "geo_kyiv.json")
+		case "Nowhere":
+			serveFixture(t, w, http.StatusNotFound, "error_404.json")
+		default:
+			serveFixture(t, w, http.StatusOK, "geo_empty.json")
+		}
+	})
+	mux.HandleFunc("/data/2.5/weather", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("lat") == "90" {
+			serveFixture(t, w, http.StatusOK, "weather_empty.json")
+			return
+		}
+		if r.URL.Query().Get("units") == "imperial" {
+			serveFixture(t, w, http.StatusOK, "weather_kyiv_imperial.json")
+			return
+		}
+		serveFixture(t, w, http.StatusOK, "weather_kyiv.json")
+	})
+	mux.HandleFunc("/data/2.5/forecast", func(w http.ResponseWriter, r *http.Request) {
+		serveFixture(t, w, http.StatusOK, "forecast_kyiv.json")
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("appid") {
+		case invalidKey:
+			serveFixture(t, w, http.StatusUnauthorized, "error_401.json")
+		case exhaustedKey:
+			serveFixture(t, w, http.StatusTooManyRequests, "error_429.json")
+		default:
+			mux.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func serveFixture(t *testing.T, w http.ResponseWriter, status int, name string) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("unable to read fixture %v: %v", name, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// newTestWeatherAPI creates weather API that talks to fake server with given API key
+func newTestWeatherAPI(server *httptest.Server, key string) *weatherAPI.WeatherAPI {
+	return newTestWeatherAPIWithKeys(server, []string{key}, 0)
+}
+
+// newTestWeatherAPIWithKeys creates weather API that talks to fake server with a pool of API keys
+func newTestWeatherAPIWithKeys(server *httptest.Server, keys []string, quota int) *weatherAPI.WeatherAPI {
+	return weatherAPI.NewWeatherAPI(&weatherAPI.WeatherConfig{
+		GeoAPI:         weatherAPI.GeoAPI{Version: "1.0", Limit: 1},
+		BaseURL:        server.URL,
+		API:            keys,
+		DailyQuota:     quota,
+		WeatherVersion: "2.5",
+		Units:          "metric",
+		Timeout:        time.Second,
+	}, server.Client())
+}
@@ -0,0 +1,81 @@
+package weatherAPI
+
+import (
+	"context"
+	"fmt"
+	"subscriptionbot/db"
+	"time"
+)
+
+// Forecast returns 5 day forecast with 3-hour step for user's city or location
+func (w *WeatherAPI) Forecast(ctx context.Context, user db.User) (ForecastData, error) {
+	var forecast ForecastData
+
+	lat, lon, coordErr := w.userCoordinates(ctx, user)
+	if coordErr != nil {
+		return ForecastData{}, coordErr
+	}
+
+	if getErr := w.get(ctx, w.getForecastURL(lat, lon, w.userUnits(user)), &forecast); getErr != nil {
+		return ForecastData{}, getErr
+	}
+
+	if len(forecast.List) == 0 {
+		return ForecastData{}, ErrEmptyResponse
+	}
+
+	return forecast, nil
+}
+
+func (w *WeatherAPI) getForecastURL(lat, lon float64, units string) string {
+	return fmt.Sprintf(w.ForecastAPI, lat, lon, units)
+}
+
+// Time returns forecast step time converted with city's timezone offset
+func (f ForecastItem) Time(timezone int) time.Time {
+	return time.Unix(int64(f.Dt), 0).In(time.FixedZone("", timezone))
+}
+
+// Precipitation returns rain and snow volume of forecast step in mm
+func (f ForecastItem) Precipitation() float64 {
+	return f.Rain.ThreeHours + f.Snow.ThreeHours
+}
+
+// Snowy reports if snow is expected during forecast step
+func (f ForecastItem) Snowy() bool {
+	id := f.Condition().ID
+	return f.Snow.ThreeHours > 0 || (id >= 600 && id < 700)
+}
+
+// Rainy reports if rain, drizzle or thunderstorm is expected during forecast step
+func (f ForecastItem) Rainy() bool {
+	id := f.Condition().ID
+	return f.Rain.ThreeHours > 0 || (id >= 200 && id < 600)
+}
+
+// FormatTrend renders min and max temperature and precipitation of the forecast
+func FormatTrend(forecast ForecastData) string {
+	if len(forecast.List) == 0 {
+		return ""
+	}
+
+	minTemp, maxTemp := forecast.List[0].Main.Temp, forecast.List[0].Main.Temp
+	precipitation := 0.0
+	for _, item := range forecast.List {
+		if item.Main.Temp < minTemp {
+			minTemp = item.Main.Temp
+		}
+		if item.Main.Temp > maxTemp {
+			maxTemp = item.Main.Temp
+		}
+		precipitation += item.Precipitation()
+	}
+	days := int(forecast.List[len(forecast.List)-1].Time(0).Sub(forecast.List[0].Time(0)).Hours()/24) + 1
+
+	text := fmt.Sprintf("Next %v days: from %v° to %v°. Precipitation %.1f mm", days, int(minTemp), int(maxTemp), precipitation)
+	if forecast.Stale {
+		text = fmt.Sprintf("%v\n⚠️Weather provider is unavailable. Showing an earlier forecast", text)
+	}
+
+	return text
+}
@@ -0,0 +1,90 @@
+package weatherAPI
+
+import (
+	"fmt"
+	"strings"
+)
+
+// iconURL for condition icon images provided by OpenWeatherMap
+const iconURL = "https://openweathermap.org/img/wn/%s@2x.png"
+
+// conditionEmojis for OpenWeatherMap condition codes that have their own emoji
+var conditionEmojis = map[int]string{
+	511: "🌨️",
+	611: "🌨️",
+	612: "🌨️",
+	613: "🌨️",
+	615: "🌨️",
+	616: "🌨️",
+	731: "🌪️",
+	751: "🌪️",
+	761: "🌪️",
+	762: "🌋",
+	771: "💨",
+	781: "🌪️",
+	802: "⛅",
+	803: "☁️",
+	804: "☁️",
+}
+
+// ConditionEmoji returns emoji for OpenWeatherMap condition code. Icon code tells if it is day or night
+func ConditionEmoji(id int, icon string) string {
+	if emoji, found := conditionEmojis[id]; found {
+		return emoji
+	}
+
+	night := strings.HasSuffix(icon, "n")
+	switch {
+	case id >= 200 && id < 300:
+		return "⛈️"
+	case id >= 300 && id < 400:
+		return "🌦️"
+	case id >= 500 && id < 600:
+		return "🌧️"
+	case id >= 600 && id < 700:
+		return "❄️"
+	case id >= 700 && id < 800:
+		return "🌫️"
+	case id == 800 && night:
+		return "🌙"
+	case id == 800:
+		return "☀️"
+	case id == 801 && night:
+		return "☁️"
+	case id == 801:
+		return "🌤️"
+	default:
+		return "🌡️"
+	}
+}
+
+// IconURL returns URL of condition icon image
+func IconURL(icon string) string {
+	return fmt.Sprintf(iconURL, icon)
+}
+
+// Emoji returns emoji for weather condition
+func (w Weather) Emoji() string {
+	return ConditionEmoji(w.ID, w.Icon)
+}
+
+// IconURL returns URL of weather condition icon image
+func (w Weather) IconURL() string {
+	return IconURL(w.Icon)
+}
+
+// Condition returns main weather condition of the response
+func (w WeatherData) Condition() Weather {
+	if len(w.Weather) == 0 {
+		return Weather{}
+	}
+	return w.Weather[0]
+}
+
+// Condition returns main weather condition of the forecast step
+func (f ForecastItem) Condition() Weather {
+	if len(f.Weather) == 0 {
+		return Weather{}
+	}
+	return f.Weather[0]
+}
@@ -0,0 +1,38 @@
+package weatherAPI_test
+
+import (
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionEmoji(t *testing.T) {
+	tests := []struct {
+		name string
+		id   int
+		icon string
+		want string
+	}{
+		{name: "thunderstorm", id: 211, icon: "11d", want: "⛈️"},
+		{name: "drizzle", id: 301, icon: "09d", want: "🌦️"},
+		{name: "rain", id: 501, icon: "10d", want: "🌧️"},
+		{name: "snow", id: 601, icon: "13d", want: "❄️"},
+		{name: "sleet", id: 611, icon: "13d", want: "🌨️"},
+		{name: "fog", id: 741, icon: "50d", want: "🌫️"},
+		{name: "clear day", id: 800, icon: "01d", want: "☀️"},
+		{name: "clear night", id: 800, icon: "01n", want: "🌙"},
+		{name: "few clouds day", id: 801, icon: "02d", want: "🌤️"},
+		{name: "few clouds night", id: 801, icon: "02n", want: "☁️"},
+		{name: "unknown code", id: 42, want: "🌡️"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, weatherAPI.ConditionEmoji(tc.id, tc.icon))
+		})
+	}
+}
+
+func TestWeather_IconURL(t *testing.T) {
+	assert.Equal(t, "https://openweathermap.org/img/wn/10n@2x.png", weatherAPI.Weather{Icon: "10n"}.IconURL())
+}
@@ -0,0 +1,121 @@
+package weatherAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+// ErrKeysExhausted is returned when every API key reached its daily quota or was rate limited today
+var ErrKeysExhausted = errors.New("all weather API keys are exhausted for today")
+
+// KeyPool rotates API keys and counts calls per key per day
+type KeyPool struct {
+	mu        sync.Mutex
+	keys      []string
+	quota     int
+	current   int
+	day       string
+	calls     map[string]int
+	exhausted map[string]bool
+}
+
+// KeyUsage struct for calls made with an API key today
+type KeyUsage struct {
+	Key       string `json:"key"`
+	Day       string `json:"day"`
+	Calls     int    `json:"calls"`
+	Quota     int    `json:"quota"`
+	Exhausted bool   `json:"exhausted"`
+}
+
+// NewKeyPool creates pool of API keys. Quota of 0 means calls are not limited
+func NewKeyPool(keys []string, quota int) *KeyPool {
+	return &KeyPool{
+		keys:      keys,
+		quota:     quota,
+		calls:     make(map[string]int),
+		exhausted: make(map[string]bool),
+	}
+}
+
+// Acquire returns key for the next call and counts the call. Rotates to the next key when quota is reached
+func (p *KeyPool) Acquire() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.resetDay(time.Now().UTC())
+	for range p.keys {
+		key := p.keys[p.current]
+		if !p.exhausted[key] && (p.quota <= 0 || p.calls[key] < p.quota) {
+			p.calls[key]++
+			return key, nil
+		}
+		p.current = (p.current + 1) % len(p.keys)
+	}
+
+	return "", ErrKeysExhausted
+}
+
+// Exhaust marks key as unusable until the end of the day and rotates to the next key
+func (p *KeyPool) Exhaust(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.exhausted[key] = true
+	if len(p.keys) > 0 && p.keys[p.current] == key {
+		p.current = (p.current + 1) % len(p.keys)
+	}
+	log.Warn().Msgf("Weather API key %v is exhausted after %v calls today", maskKey(key), p.calls[key])
+}
+
+// Usage returns today's calls for every key. Keys are masked
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.resetDay(time.Now().UTC())
+	usage := make([]KeyUsage, 0, len(p.keys))
+	for _, key := range p.keys {
+		usage = append(usage, KeyUsage{
+			Key:       maskKey(key),
+			Day:       p.day,
+			Calls:     p.calls[key],
+			Quota:     p.quota,
+			Exhausted: p.exhausted[key] || (p.quota > 0 && p.calls[key] >= p.quota),
+		})
+	}
+
+	return usage
+}
+
+// UsageHandler reports today's calls for every API key
+func (p *KeyPool) UsageHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p.Usage()); err != nil {
+		log.Error().Err(err).Msg("unable to write weather API key usage")
+	}
+}
+
+// resetDay clears counters when a new UTC day starts
+func (p *KeyPool) resetDay(now time.Time) {
+	day := now.Format("2006-01-02")
+	if p.day == day {
+		return
+	}
+	p.day = day
+	p.current = 0
+	p.calls = make(map[string]int)
+	p.exhausted = make(map[string]bool)
+}
+
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
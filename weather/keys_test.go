@@ -0,0 +1,50 @@
+package weatherAPI_test
+
+import (
+	"context"
+	"subscriptionbot/db"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPool_RotateOnRateLimit(t *testing.T) {
+	server := newFakeOpenWeather(t)
+	api := newTestWeatherAPIWithKeys(server, []string{exhaustedKey, "first-valid", "second-valid"}, 0)
+	user := db.User{Location: db.Location{Latitude: 50.45, Longitude: 30.52}}
+
+	for i := 0; i < 2; i++ {
+		_, err := api.CurrentWeather(context.Background(), user)
+		require.NoError(t, err)
+	}
+
+	usage := api.Keys().Usage()
+	require.Len(t, usage, 3)
+	assert.Equal(t, 1, usage[0].Calls)
+	assert.True(t, usage[0].Exhausted)
+	assert.Equal(t, 2, usage[1].Calls)
+	assert.False(t, usage[1].Exhausted)
+	assert.Equal(t, 0, usage[2].Calls)
+	assert.Equal(t, "****alid", usage[1].Key)
+}
+
+func TestKeyPool_Quota(t *testing.T) {
+	server := newFakeOpenWeather(t)
+	api := newTestWeatherAPIWithKeys(server, []string{"first-valid", "second-valid"}, 2)
+
+	//City lookup and weather request are counted separately
+	_, err := api.CurrentWeather(context.Background(), db.User{City: "Kyiv"})
+	require.NoError(t, err)
+	_, err = api.CurrentWeather(context.Background(), db.User{City: "Kyiv"})
+	require.NoError(t, err)
+
+	_, err = api.CurrentWeather(context.Background(), db.User{City: "Kyiv"})
+	assert.ErrorIs(t, err, weatherAPI.ErrKeysExhausted)
+
+	for _, usage := range api.Keys().Usage() {
+		assert.Equal(t, 2, usage.Calls)
+		assert.True(t, usage.Exhausted)
+	}
+}
@@ -1,11 +1,22 @@
 package weatherAPI
 
+import "time"
+
 // WeatherConfig struct for weather config
 type WeatherConfig struct {
 	GeoAPI         GeoAPI
-	API            string `env:"API"`
-	WeatherVersion string `env:"WEATHER_VERSION"`
-	Units          string `env:"UNITS"`
+	BaseURL        string        `env:"WEATHER_BASE_URL" envDefault:"https://api.openweathermap.org"`
+	API            []string      `env:"API" envSeparator:","`
+	DailyQuota     int           `env:"API_DAILY_QUOTA" envDefault:"1000"`
+	WeatherVersion string        `env:"WEATHER_VERSION"`
+	Units          string        `env:"UNITS"`
+	Timeout        time.Duration `env:"WEATHER_TIMEOUT" envDefault:"10s"`
+}
+
+// BreakerConfig struct for weather provider circuit breaker config
+type BreakerConfig struct {
+	Threshold int           `env:"BREAKER_THRESHOLD" envDefault:"5"`
+	Cooldown  time.Duration `env:"BREAKER_COOLDOWN" envDefault:"1m"`
 }
 
 // GeoAPI for API that returns lat, lon for city provided by user
@@ -75,6 +86,7 @@ type WeatherData struct {
 	Visibility int       `json:"visibility"`
 	Wind       Wind      `json:"wind"`
 	Rain       Rain      `json:"rain"`
+	Snow       Snow      `json:"snow"`
 	Clouds     Clouds    `json:"clouds"`
 	Dt         int       `json:"dt"`
 	Sys        Sys       `json:"sys"`
@@ -82,6 +94,7 @@ type WeatherData struct {
 	ID         int       `json:"id"`
 	Name       string    `json:"name"`
 	Cod        int       `json:"cod"`
+	Stale      bool      `json:"-"`
 }
 
 // LocalNames struct for local names
@@ -99,3 +112,46 @@ type Location struct {
 	Country    string     `json:"country"`
 	State      string     `json:"state"`
 }
+
+// Snow struct for snow details
+type Snow struct {
+	OneHour float64 `json:"1h"`
+}
+
+// Precipitation struct for precipitation volume of a forecast step
+type Precipitation struct {
+	ThreeHours float64 `json:"3h"`
+}
+
+// ForecastItem struct for a single 3-hour forecast step
+type ForecastItem struct {
+	Dt      int           `json:"dt"`
+	Main    Main          `json:"main"`
+	Weather []Weather     `json:"weather"`
+	Clouds  Clouds        `json:"clouds"`
+	Wind    Wind          `json:"wind"`
+	Pop     float64       `json:"pop"`
+	Rain    Precipitation `json:"rain"`
+	Snow    Precipitation `json:"snow"`
+	DtTxt   string        `json:"dt_txt"`
+}
+
+// ForecastCity struct for city the forecast is made for
+type ForecastCity struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Coord    Coord  `json:"coord"`
+	Country  string `json:"country"`
+	Timezone int    `json:"timezone"`
+	Sunrise  int    `json:"sunrise"`
+	Sunset   int    `json:"sunset"`
+}
+
+// ForecastData struct for 5 day forecast with 3-hour step
+type ForecastData struct {
+	Cod   string         `json:"cod"`
+	Cnt   int            `json:"cnt"`
+	List  []ForecastItem `json:"list"`
+	City  ForecastCity   `json:"city"`
+	Stale bool           `json:"-"`
+}
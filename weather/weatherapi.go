@@ -1,14 +1,15 @@
 package weatherAPI
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"subscriptionbot/db"
 	"sync"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 	"github.com/phuslu/log"
@@ -17,15 +18,25 @@ import (
 )
 
 type WeatherService interface {
-	WeatherRequest(user db.User) (url.Values, error)
+	WeatherRequest(ctx context.Context, user db.User) (url.Values, error)
+	CurrentWeather(ctx context.Context, user db.User) (WeatherData, error)
+	Forecast(ctx context.Context, user db.User) (ForecastData, error)
 }
 
 // WeatherAPI struct for Geo and Weather APIs
 type WeatherAPI struct {
-	GeoAPI     string
-	WeatherAPI string
+	GeoAPI      string
+	WeatherAPI  string
+	ForecastAPI string
+	client      HTTPClient
+	timeout     time.Duration
+	keys        *KeyPool
+	units       string
 }
 
+// defaultTimeout of weather requests if configured timeout isn't positive
+const defaultTimeout = 10 * time.Second
+
 var (
 	lock             = sync.Mutex{}
 	singleWeatherAPI *WeatherAPI
@@ -41,89 +52,118 @@ func GetWeatherAPI() *WeatherAPI {
 			if err := env.Parse(cfg); err != nil {
 				log.Error().Err(err)
 			}
-			singleWeatherAPI = &WeatherAPI{
-				GeoAPI:     fmt.Sprintf("http://api.openweathermap.org/geo/%s/direct?q=%%v&limit=%v&appid=%v", cfg.GeoAPI.Version, cfg.GeoAPI.Limit, cfg.API),
-				WeatherAPI: fmt.Sprintf("https://api.openweathermap.org/data/%s/weather?lat=%%v&lon=%%v&appid=%v&units=%s", cfg.WeatherVersion, cfg.API, cfg.Units),
-			}
+			singleWeatherAPI = NewWeatherAPI(cfg, &http.Client{})
 			log.Info().Msg("Weather API created")
 		}
 	}
 	return singleWeatherAPI
 }
 
-// WeatherRequest function handles weather API requests
-func (w *WeatherAPI) WeatherRequest(user db.User) (url.Values, error) {
-	var (
-		weather WeatherData
-		lat     float64
-		lon     float64
-		cityErr error
-	)
-	//Checking if response is empty fixed the bug when it returns the weather for the Globe when user input was empty
-	if isResponseEmpty(user) {
-		return url.Values{}, fmt.Errorf("response body is nil")
+// NewWeatherAPI creates weather API for provider at configured base URL. Default timeout is used if configured one isn't positive
+func NewWeatherAPI(cfg *WeatherConfig, client HTTPClient) *WeatherAPI {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
 	}
 
-	lat = user.Location.Latitude
-	lon = user.Location.Longitude
+	return &WeatherAPI{
+		GeoAPI:      fmt.Sprintf("%s/geo/%s/direct?q=%%v&limit=%v", baseURL, cfg.GeoAPI.Version, cfg.GeoAPI.Limit),
+		WeatherAPI:  fmt.Sprintf("%s/data/%s/weather?lat=%%v&lon=%%v&units=%%v", baseURL, cfg.WeatherVersion),
+		ForecastAPI: fmt.Sprintf("%s/data/%s/forecast?lat=%%v&lon=%%v&units=%%v", baseURL, cfg.WeatherVersion),
+		client:      client,
+		timeout:     timeout,
+		keys:        NewKeyPool(cfg.API, cfg.DailyQuota),
+		units:       cfg.Units,
+	}
+}
 
-	if user.City != "" {
-		lat, lon, cityErr = w.GetWeatherByCityName(user.City)
-		if cityErr != nil {
-			return url.Values{}, cityErr
-		}
+// WeatherRequest function handles weather API requests
+func (w *WeatherAPI) WeatherRequest(ctx context.Context, user db.User) (url.Values, error) {
+	weather, weatherErr := w.CurrentWeather(ctx, user)
+	if weatherErr != nil {
+		return url.Values{}, weatherErr
 	}
-	resp, respErr := http.Get(w.getWeatherURL(lat, lon))
-	if respErr != nil {
-		return url.Values{}, respErr
+
+	return url.Values{
+		"chat_id": {strconv.Itoa(user.ChatID)},
+		"text":    {FormatForecast(weather)},
+	}, nil
+
+}
+
+// CurrentWeather returns current weather data for user's city or location
+func (w *WeatherAPI) CurrentWeather(ctx context.Context, user db.User) (WeatherData, error) {
+	var weather WeatherData
+
+	lat, lon, coordErr := w.userCoordinates(ctx, user)
+	if coordErr != nil {
+		return WeatherData{}, coordErr
 	}
 
-	respBody, respBodyErr := io.ReadAll(resp.Body)
-	if respBodyErr != nil {
-		return url.Values{}, respBodyErr
+	if getErr := w.get(ctx, w.getWeatherURL(lat, lon, w.userUnits(user)), &weather); getErr != nil {
+		return WeatherData{}, getErr
 	}
 
-	marshalErr := json.Unmarshal(respBody, &weather)
-	if marshalErr != nil {
-		log.Error().Err(fmt.Errorf("error unmarshalling JSON for holiday API request Error:%w", marshalErr))
+	if len(weather.Weather) == 0 {
+		return WeatherData{}, ErrEmptyResponse
 	}
 
-	text := fmt.Sprintf("Today is %v in %v\n🌡️Temperature %v°. Feels like %v°\n💨Wind speed %v", weather.Weather[0].Description, weather.Name, int(weather.Main.Temp), int(weather.Main.FeelsLike), float32(weather.Wind.Speed))
+	return weather, nil
+}
 
-	return url.Values{
-		"chat_id": {strconv.Itoa(user.ChatID)},
-		"text":    {text},
-	}, nil
+// FormatForecast renders weather data as a forecast message
+func FormatForecast(weather WeatherData) string {
+	text := fmt.Sprintf("%vToday is %v in %v\n🌡️Temperature %v°. Feels like %v°\n💨Wind speed %v", weather.Condition().Emoji(), weather.Condition().Description, weather.Name, int(weather.Main.Temp), int(weather.Main.FeelsLike), float32(weather.Wind.Speed))
+	if daylight := FormatDaylight(weather); daylight != "" {
+		text = fmt.Sprintf("%v\n%v", text, daylight)
+	}
+	if weather.Stale {
+		text = fmt.Sprintf("%v\n⚠️Weather provider is unavailable. Showing data from %v", text, time.Unix(int64(weather.Dt), 0).In(weather.zone()).Format("02.01 15:04"))
+	}
 
+	return text
 }
 
-func (w *WeatherAPI) GetWeatherByCityName(text string) (float64, float64, error) {
-	var location []Location
-
-	resp, err := http.Get(fmt.Sprintf(w.GeoAPI, cases.Title(language.Und, cases.NoLower).String(text)))
-	if err != nil {
-		return 0.0, 0.0, fmt.Errorf("something went wrong during api request for city coordinates%w", err)
+// userCoordinates returns lat, lon of user's city or shared location
+func (w *WeatherAPI) userCoordinates(ctx context.Context, user db.User) (float64, float64, error) {
+	//Checking if response is empty fixed the bug when it returns the weather for the Globe when user input was empty
+	if isResponseEmpty(user) {
+		return 0.0, 0.0, ErrNoLocation
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0.0, 0.0, fmt.Errorf("unable to read response body for city provided by user: %w", err)
+	if user.City != "" {
+		return w.GetWeatherByCityName(ctx, user.City)
 	}
 
-	marshalErr := json.Unmarshal(respBody, &location)
-	if marshalErr != nil {
-		return 0.0, 0.0, fmt.Errorf("json unmarshal error for city provided by user: %w", marshalErr)
+	return user.Location.Latitude, user.Location.Longitude, nil
+}
+
+func (w *WeatherAPI) GetWeatherByCityName(ctx context.Context, text string) (float64, float64, error) {
+	var location []Location
+
+	city := cases.Title(language.Und, cases.NoLower).String(text)
+	if err := w.get(ctx, fmt.Sprintf(w.GeoAPI, url.QueryEscape(city)), &location); err != nil {
+		return 0.0, 0.0, fmt.Errorf("something went wrong during api request for city coordinates: %w", err)
 	}
 
 	if len(location) == 0 {
-		return 0.0, 0.0, fmt.Errorf("geo response is empty. Invalid city")
+		return 0.0, 0.0, fmt.Errorf("geo response is empty. Invalid city: %w", ErrNotFound)
 	}
 
 	return location[0].Lat, location[0].Lon, nil
 }
 
-func (w *WeatherAPI) getWeatherURL(lat, lon float64) string {
-	return fmt.Sprintf(w.WeatherAPI, lat, lon)
+func (w *WeatherAPI) getWeatherURL(lat, lon float64, units string) string {
+	return fmt.Sprintf(w.WeatherAPI, lat, lon, units)
+}
+
+// userUnits returns units chosen by user or configured default
+func (w *WeatherAPI) userUnits(user db.User) string {
+	if user.Units != "" {
+		return user.Units
+	}
+	return w.units
 }
 
 func isResponseEmpty(user db.User) bool {
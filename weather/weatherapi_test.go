@@ -0,0 +1,199 @@
+package weatherAPI_test
+
+import (
+	"context"
+	"net/url"
+	"subscriptionbot/db"
+	weatherAPI "subscriptionbot/weather"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeatherAPI_WeatherRequest(t *testing.T) {
+	server := newFakeOpenWeather(t)
+
+	tests := []struct {
+		name          string
+		key           string
+		user          db.User
+		want          url.Values
+		expectedError error
+	}{
+		{
+			name: "city",
+			key:  "valid",
+			user: db.User{City: "kyiv", ChatID: 358383178},
+			want: url.Values{
+				"chat_id": {"358383178"},
+				"text":    {"☀️Today is clear sky in Kyiv\n🌡️Temperature 12°. Feels like 11°\n💨Wind speed 3.5\n🌅Sunrise 06:20. 🌇Sunset 17:52\nDay length 11h 31m"},
+			},
+		},
+		{
+			name: "shared location",
+			key:  "valid",
+			user: db.User{Location: db.Location{Latitude: 50.45, Longitude: 30.52}, ChatID: 358383178},
+			want: url.Values{
+				"chat_id": {"358383178"},
+				"text":    {"☀️Today is clear sky in Kyiv\n🌡️Temperature 12°. Feels like 11°\n💨Wind speed 3.5\n🌅Sunrise 06:20. 🌇Sunset 17:52\nDay length 11h 31m"},
+			},
+		},
+		{
+			name:          "no city or location",
+			key:           "valid",
+			user:          db.User{ChatID: 358383178},
+			expectedError: weatherAPI.ErrNoLocation,
+		},
+		{
+			name:          "unknown city",
+			key:           "valid",
+			user:          db.User{City: "Atlantis"},
+			expectedError: weatherAPI.ErrNotFound,
+		},
+		{
+			name:          "city not found",
+			key:           "valid",
+			user:          db.User{City: "Nowhere"},
+			expectedError: weatherAPI.ErrNotFound,
+		},
+		{
+			name:          "invalid API key",
+			key:           invalidKey,
+			user:          db.User{City: "Kyiv"},
+			expectedError: weatherAPI.ErrUnauthorized,
+		},
+		{
+			name:          "rate limited",
+			key:           exhaustedKey,
+			user:          db.User{Location: db.Location{Latitude: 50.45, Longitude: 30.52}},
+			expectedError: weatherAPI.ErrRateLimited,
+		},
+		{
+			name:          "no weather conditions",
+			key:           "valid",
+			user:          db.User{Location: db.Location{Latitude: 90, Longitude: 0}},
+			expectedError: weatherAPI.ErrEmptyResponse,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newTestWeatherAPI(server, tc.key).WeatherRequest(context.Background(), tc.user)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestWeatherAPI_GetWeatherByCityName(t *testing.T) {
+	server := newFakeOpenWeather(t)
+
+	tests := []struct {
+		name          string
+		key           string
+		city          string
+		wantLat       float64
+		wantLon       float64
+		expectedError error
+	}{
+		{
+			name:    "city found",
+			key:     "valid",
+			city:    "kyiv",
+			wantLat: 50.4500336,
+			wantLon: 30.5241361,
+		},
+		{
+			name:          "empty geo response",
+			key:           "valid",
+			city:          "Atlantis",
+			expectedError: weatherAPI.ErrNotFound,
+		},
+		{
+			name:          "geo responds with 404",
+			key:           "valid",
+			city:          "Nowhere",
+			expectedError: weatherAPI.ErrNotFound,
+		},
+		{
+			name:          "invalid API key",
+			key:           invalidKey,
+			city:          "Kyiv",
+			expectedError: weatherAPI.ErrUnauthorized,
+		},
+		{
+			name:          "rate limited",
+			key:           exhaustedKey,
+			city:          "Kyiv",
+			expectedError: weatherAPI.ErrRateLimited,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lat, lon, err := newTestWeatherAPI(server, tc.key).GetWeatherByCityName(context.Background(), tc.city)
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, tc.wantLat, lat)
+			assert.Equal(t, tc.wantLon, lon)
+		})
+	}
+}
+
+func TestWeatherAPI_Forecast(t *testing.T) {
+	server := newFakeOpenWeather(t)
+
+	forecast, err := newTestWeatherAPI(server, "valid").Forecast(context.Background(), db.User{City: "Kyiv"})
+	require.NoError(t, err)
+	require.Len(t, forecast.List, 4)
+	assert.Equal(t, "Kyiv", forecast.City.Name)
+	assert.Equal(t, 1.2, forecast.List[2].Precipitation())
+	assert.Equal(t, 17, forecast.List[0].Time(forecast.City.Timezone).Hour())
+
+	chart, chartErr := weatherAPI.RenderChart(forecast)
+	require.NoError(t, chartErr)
+	assert.Equal(t, []byte("\x89PNG"), chart[:4])
+}
+
+func TestWeatherAPI_CurrentWeather_units(t *testing.T) {
+	server := newFakeOpenWeather(t)
+	weather := newTestWeatherAPI(server, "valid")
+
+	tests := []struct {
+		name  string
+		units string
+		want  float64
+	}{
+		{name: "default units", want: 12.34},
+		{name: "metric", units: "metric", want: 12.34},
+		{name: "imperial", units: "imperial", want: 54.21},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := weather.CurrentWeather(context.Background(), db.User{City: "Kyiv", Units: tc.units})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Main.Temp)
+		})
+	}
+}
+
+func TestNewWeatherAPI_timeout(t *testing.T) {
+	server := newFakeOpenWeather(t)
+
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		weather := weatherAPI.NewWeatherAPI(&weatherAPI.WeatherConfig{
+			GeoAPI:         weatherAPI.GeoAPI{Version: "1.0", Limit: 1},
+			BaseURL:        server.URL,
+			API:            []string{"valid"},
+			WeatherVersion: "2.5",
+			Timeout:        timeout,
+		}, server.Client())
+
+		got, err := weather.CurrentWeather(context.Background(), db.User{City: "Kyiv"})
+		require.NoError(t, err, "timeout %v", timeout)
+		assert.Equal(t, 12.34, got.Main.Temp)
+	}
+}